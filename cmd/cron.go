@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// cronCmd represents the cron command
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Manage cron tasks",
+	Long: `my-orchestrator cron command.
+
+A cron task starts a new task from its template on every activation of a
+five field cron schedule, such as "0 2 * * *" for every night at 2am.`,
+}
+
+var cronCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create or replace a cron task from a specification file",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Unable to read file: %v", filename)
+		}
+
+		url := fmt.Sprintf("http://%s/crontasks", manager)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Println("Successfully sent cron task to manager")
+	},
+}
+
+var cronListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cron tasks",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/crontasks", manager)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var cronTasks []*task.CronTask
+		err = json.NewDecoder(resp.Body).Decode(&cronTasks)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "NAME\tSCHEDULE\tTIMEZONE\tCONCURRENCY\tLAST RUN\tNEXT RUN\t")
+		for _, c := range cronTasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", c.Name, c.Schedule, c.TimeZone, c.ConcurrencyPolicy,
+				formatCronTime(c.LastScheduleTime), formatCronTime(c.NextScheduleTime))
+		}
+		w.Flush()
+	},
+}
+
+var cronRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a cron task",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/crontasks/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Cron task %s has been removed.", args[0])
+	},
+}
+
+func formatCronTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronCreateCmd, cronListCmd, cronRemoveCmd)
+
+	cronCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	cronCreateCmd.Flags().StringP("filename", "f", "crontask.json", "Cron task specification file")
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/moby/term"
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec <taskID> -- <command> [args...]",
+	Short: "Run a command inside a running task",
+	Long: `my-orchestrator exec command.
+
+The exec command runs a command inside the container of a running task.
+Use -i to attach stdin and -t to allocate a TTY, e.g.
+
+  my-orchestrator exec -it <taskID> -- /bin/sh`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		tty, _ := cmd.Flags().GetBool("tty")
+
+		opts := task.ExecOptions{
+			Cmd:   args[1:],
+			Tty:   tty,
+			Stdin: interactive,
+		}
+		if tty {
+			ws, err := term.GetWinsize(os.Stdout.Fd())
+			if err == nil {
+				opts.Height = uint(ws.Height)
+				opts.Width = uint(ws.Width)
+			}
+		}
+
+		data, err := json.Marshal(opts)
+		if err != nil {
+			log.Fatalf("Unable to marshal exec options: %v", err)
+		}
+
+		conn, err := net.Dial("tcp", manager)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", manager, err)
+		}
+		defer conn.Close()
+
+		url := fmt.Sprintf("http://%s/tasks/%s/exec", manager, args[0])
+		req, _ := http.NewRequest("POST", url, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "tcp")
+		err = req.Write(conn)
+		if err != nil {
+			log.Fatalf("Error sending request: %v", err)
+		}
+
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, req)
+		if err != nil {
+			log.Fatalf("Error reading response: %v", err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+
+		var state *term.State
+		if tty && term.IsTerminal(os.Stdin.Fd()) {
+			state, err = term.MakeRaw(os.Stdin.Fd())
+			if err != nil {
+				log.Fatalf("Unable to put terminal in raw mode: %v", err)
+			}
+		}
+
+		if interactive {
+			go func() {
+				io.Copy(conn, os.Stdin)
+				if tc, ok := conn.(*net.TCPConn); ok {
+					tc.CloseWrite()
+				}
+			}()
+		}
+
+		if tty {
+			io.Copy(os.Stdout, r)
+		} else {
+			stdcopy.StdCopy(os.Stdout, os.Stderr, r)
+		}
+
+		if state != nil {
+			term.RestoreTerminal(os.Stdin.Fd(), state)
+		}
+
+		code := execExitCode(manager, args[0], resp.Header.Get("X-Exec-Id"))
+		if code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	execCmd.Flags().BoolP("interactive", "i", false, "Keep stdin open and send it to the command")
+	execCmd.Flags().BoolP("tty", "t", false, "Allocate a TTY")
+}
+
+func execExitCode(manager string, taskID string, execID string) int {
+	url := fmt.Sprintf("http://%s/tasks/%s/exec/%s", manager, taskID, execID)
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Printf("Error connecting to %v: %v", url, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		printErrResponse(resp)
+		return 1
+	}
+
+	result := task.ExecResult{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		log.Printf("Error decoding response: %v", err)
+		return 1
+	}
+	return result.ExitCode
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// groupCmd represents the group command
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage task groups",
+	Long: `my-orchestrator group command.
+
+A task group is a set of tasks that run together on one worker, such as an
+application and its sidecars. Init tasks run to completion first, then the
+tasks are started in order, sharing a network namespace and volumes.`,
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a task group from a specification file",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Unable to read file: %v", filename)
+		}
+
+		url := fmt.Sprintf("http://%s/groups", manager)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Println("Successfully sent task group to manager")
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List task groups",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/groups", manager)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var groups []*task.TaskGroup
+		err = json.NewDecoder(resp.Body).Decode(&groups)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "NAME\tTASKS\tNODE\tSTATE\tAGE\t")
+		for _, g := range groups {
+			age := units.HumanDuration(time.Now().UTC().Sub(g.CreatedAt))
+			fmt.Fprintf(w, "%s\t%d+%d\t%s\t%s\t%s\t\n", g.Name, len(g.InitTasks), len(g.Tasks), g.Node, g.State, age)
+		}
+		w.Flush()
+	},
+}
+
+var groupStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show the tasks of a task group",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/groups/%s", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		g := task.TaskGroup{}
+		err = json.NewDecoder(resp.Body).Decode(&g)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		url = fmt.Sprintf("http://%s/tasks", manager)
+		resp, err = http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		var tasks []*task.Task
+		err = json.NewDecoder(resp.Body).Decode(&tasks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		byID := make(map[uuid.UUID]*task.Task)
+		for _, t := range tasks {
+			byID[t.ID] = t
+		}
+
+		fmt.Printf("Task group %s on %s: %s\n", g.Name, g.Node, g.State)
+		if g.Reason != "" {
+			fmt.Printf("  %s\n", g.Reason)
+		}
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "KIND\tID\tNAME\tSTATE\tREASON\t")
+		printTasks := func(kind string, ids []uuid.UUID) {
+			for _, id := range ids {
+				t, ok := byID[id]
+				if !ok {
+					fmt.Fprintf(w, "%s\t%s\t\t\t\t\n", kind, id)
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", kind, t.ID, t.Name, t.State.String()[t.State], t.Reason)
+			}
+		}
+		printTasks("init", g.InitTaskIDs)
+		printTasks("task", g.TaskIDs)
+		w.Flush()
+	},
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Stop the tasks of a task group and remove it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/groups/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Task group %s has been removed.", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupCreateCmd, groupListCmd, groupStatusCmd, groupRemoveCmd)
+
+	groupCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	groupCreateCmd.Flags().StringP("filename", "f", "group.json", "Task group specification file")
+}
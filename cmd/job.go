@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// jobCmd represents the job command
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Manage batch jobs",
+	Long: `my-orchestrator job command.
+
+A job runs a task template to completion a number of times. Tasks that exit
+with code 0 count as completions, failed tasks are retried up to the
+backoff limit of the job.`,
+}
+
+var jobCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a job from a specification file",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Unable to read file: %v", filename)
+		}
+
+		url := fmt.Sprintf("http://%s/jobs", manager)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Println("Successfully sent job to manager")
+	},
+}
+
+var jobListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List jobs",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/jobs", manager)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var jobs []*task.Job
+		err = json.NewDecoder(resp.Body).Decode(&jobs)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "NAME\tCOMPLETIONS\tACTIVE\tFAILED\tSTATE\tAGE\t")
+		for _, j := range jobs {
+			age := units.HumanDuration(time.Now().UTC().Sub(j.CreatedAt))
+			fmt.Fprintf(w, "%s\t%d/%d\t%d\t%d\t%s\t%s\t\n", j.Name, j.Succeeded, j.Completions, j.Active, j.Failed, jobState(j), age)
+		}
+		w.Flush()
+	},
+}
+
+var jobStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show the status of a job",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/jobs/%s", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+
+		j := task.Job{}
+		err = json.NewDecoder(resp.Body).Decode(&j)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Job %s: %s\n", j.Name, jobState(&j))
+		fmt.Printf("  Completions:  %d/%d\n", j.Succeeded, j.Completions)
+		fmt.Printf("  Parallelism:  %d\n", j.Parallelism)
+		fmt.Printf("  Active:       %d\n", j.Active)
+		fmt.Printf("  Failed:       %d (backoff limit %d)\n", j.Failed, j.BackoffLimit)
+		if j.ActiveDeadline > 0 {
+			fmt.Printf("  Deadline:     %ds\n", j.ActiveDeadline)
+		}
+		if !j.StartTime.IsZero() {
+			fmt.Printf("  Started:      %s\n", j.StartTime.Format(time.RFC3339))
+		}
+		if !j.CompletionTime.IsZero() {
+			fmt.Printf("  Finished:     %s\n", j.CompletionTime.Format(time.RFC3339))
+		}
+	},
+}
+
+var jobRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Stop the tasks of a job and remove it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/jobs/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Job %s has been removed.", args[0])
+	},
+}
+
+func jobState(j *task.Job) string {
+	if j.Reason != "" {
+		return fmt.Sprintf("%s (%s)", j.State, j.Reason)
+	}
+	return j.State
+}
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+	jobCmd.AddCommand(jobCreateCmd, jobListCmd, jobStatusCmd, jobRemoveCmd)
+
+	jobCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	jobCreateCmd.Flags().StringP("filename", "f", "job.json", "Job specification file")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/manager"
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs <taskID>",
+	Short: "Print the logs of a task",
+	Long: `my-orchestrator logs command.
+
+The logs command fetches the output of a task from the worker running it,
+through the manager. Use -f to keep streaming new output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		follow, _ := cmd.Flags().GetBool("follow")
+		tail, _ := cmd.Flags().GetString("tail")
+		since, _ := cmd.Flags().GetString("since")
+		stdout, _ := cmd.Flags().GetBool("stdout")
+		stderr, _ := cmd.Flags().GetBool("stderr")
+
+		q := url.Values{}
+		q.Set("follow", strconv.FormatBool(follow))
+		q.Set("stdout", strconv.FormatBool(stdout))
+		q.Set("stderr", strconv.FormatBool(stderr))
+		if tail != "" {
+			q.Set("tail", tail)
+		}
+		if since != "" {
+			q.Set("since", since)
+		}
+
+		u := fmt.Sprintf("http://%s/tasks/%s/logs?%s", manager, args[0], q.Encode())
+		resp, err := http.Get(u)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", u, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		io.Copy(os.Stdout, resp.Body)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	logsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	logsCmd.Flags().String("tail", "all", "Number of lines to show from the end of the logs")
+	logsCmd.Flags().String("since", "", "Show logs since a timestamp or relative duration (e.g. 10m)")
+	logsCmd.Flags().Bool("stdout", true, "Show stdout")
+	logsCmd.Flags().Bool("stderr", true, "Show stderr")
+}
+
+// printErrResponse logs the error returned by the manager API, falling back
+// to the HTTP status when the body is not an ErrResponse.
+func printErrResponse(resp *http.Response) {
+	e := manager.ErrResponse{}
+	err := json.NewDecoder(resp.Body).Decode(&e)
+	if err != nil || e.Message == "" {
+		log.Printf("Error sending request: %v", resp.Status)
+		return
+	}
+	log.Printf("Response error (%d): %s", e.HTTPStatusCode, e.Message)
+}
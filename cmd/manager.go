@@ -27,13 +27,28 @@ The manager controls the orchestration system and is responsible for:
 		workers, _ := cmd.Flags().GetStringSlice("workers")
 		scheduler, _ := cmd.Flags().GetString("scheduler")
 		dbType, _ := cmd.Flags().GetString("dbtype")
+		nodeLostAfter, _ := cmd.Flags().GetDuration("node-lost-after")
+		processInterval, _ := cmd.Flags().GetDuration("process-interval")
+		updateInterval, _ := cmd.Flags().GetDuration("update-interval")
+		restartInterval, _ := cmd.Flags().GetDuration("restart-interval")
+		reconcileInterval, _ := cmd.Flags().GetDuration("reconcile-interval")
 
 		log.Println("Starting manager")
 		m := manager.New(workers, scheduler, dbType)
+		m.NodeLostAfter = nodeLostAfter
+		m.ProcessInterval = processInterval
+		m.UpdateInterval = updateInterval
+		m.RestartInterval = restartInterval
+		m.ReconcileInterval = reconcileInterval
 		api := manager.Api{Address: host, Port: port, Manager: m}
 		go m.ProcessTasks()
 		go m.UpdateTasks()
-		go m.DoHealthChecks()
+		go m.RestartTasks()
+		go m.ReconcileServices()
+		go m.ReconcileJobs()
+		go m.ScheduleCronTasks()
+		go m.ReconcileWorkflows()
+		go m.ReconcileGroups()
 		log.Printf("Starting manager API on http://%s:%d", host, port)
 		api.Start()
 	},
@@ -45,7 +60,14 @@ func init() {
 	managerCmd.Flags().StringP("host", "H", "0.0.0.0", "Hostname or IP address")
 	managerCmd.Flags().IntP("port", "p", 5555, "Port on which to listen")
 	managerCmd.Flags().StringSliceP("workers", "w", []string{"localhost:5556"},
-		"List of workers on which the manager will schedule tasks")
+		"List of workers on which the manager will schedule tasks, in addition to workers that register themselves")
 	managerCmd.Flags().StringP("scheduler", "s", "epvm", "Name of scheduler to use (\"epvm\" or \"roundrobin\")")
 	managerCmd.Flags().StringP("dbtype", "d", "memory", "Type of datastore to use for tasks (\"memory\" or \"persistent\")")
+	managerCmd.Flags().Duration("node-lost-after", manager.DefaultNodeLostAfter,
+		"How long a worker may be unreachable before its tasks are rescheduled")
+	managerCmd.Flags().Duration("process-interval", manager.DefaultProcessInterval, "How often queued tasks are sent to workers")
+	managerCmd.Flags().Duration("update-interval", manager.DefaultUpdateInterval, "How often workers are polled for task updates")
+	managerCmd.Flags().Duration("restart-interval", manager.DefaultRestartInterval, "How often stopped tasks are checked for a restart")
+	managerCmd.Flags().Duration("reconcile-interval", manager.DefaultReconcileInterval,
+		"How often services, jobs, workflows and task groups are reconciled")
 }
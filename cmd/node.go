@@ -4,13 +4,19 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 	"github.com/utsab818/my-orchestrator/node"
 )
@@ -26,7 +32,11 @@ The node command allows a user to get the information about the nodes in the clu
 		manager, _ := cmd.Flags().GetString("manager")
 		url := fmt.Sprintf("http://%s/nodes", manager)
 
-		resp, _ := http.Get(url)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Printf("Error connecting to %v: %v", url, err)
+			return
+		}
 
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
@@ -34,17 +44,205 @@ The node command allows a user to get the information about the nodes in the clu
 		json.Unmarshal(body, &nodes)
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
-		fmt.Fprintln(w, "NAME\tMEMORY (MiB)\tDISK (GiB)\tROLE\tTASKS\t")
+		fmt.Fprintln(w, "NAME\tSTATE\tSCHEDULING\tLAST CONTACT\tCPU\tMEMORY (MiB)\tDISK (GiB)\tROLE\tTASKS\tLABELS\tTAINTS\t")
 
 		for _, node := range nodes {
-			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\t\n", node.Name, node.Memory/1000, node.Disk/1000/1000/1000, node.Role, node.TaskCount)
+			contact := fmt.Sprintf("%s ago", units.HumanDuration(time.Now().UTC().Sub(node.LastContact)))
+			var labels []string
+			for k, v := range node.Labels {
+				labels = append(labels, k+"="+v)
+			}
+			slices.Sort(labels)
+			var taints []string
+			for _, t := range node.Taints {
+				taints = append(taints, t.String())
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f/%d\t%d/%d\t%d/%d\t%s\t%d\t%s\t%s\t\n", node.Name, node.State, node.Scheduling, contact,
+				node.CpuAllocated, node.Cores, node.MemoryAllocated/1000, node.Memory/1000,
+				node.DiskAllocated/1000/1000/1000, node.Disk/1000/1000/1000, node.Role, node.TaskCount, strings.Join(labels, ","), strings.Join(taints, ","))
 		}
 		w.Flush()
 	},
 }
 
+var nodeRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a node and reschedule its tasks",
+	Long: `Remove a node from the cluster. Its tasks are rescheduled onto other nodes.
+A worker started with --manager registers again unless it is shut down.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/nodes/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Node %s has been removed.", args[0])
+	},
+}
+
+var nodeCordonCmd = &cobra.Command{
+	Use:   "cordon <name>",
+	Short: "Stop scheduling new tasks on a node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		postNodeAction(manager, args[0], "cordon", http.StatusOK)
+		log.Printf("Node %s cordoned.", args[0])
+	},
+}
+
+var nodeUncordonCmd = &cobra.Command{
+	Use:   "uncordon <name>",
+	Short: "Resume scheduling new tasks on a node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		postNodeAction(manager, args[0], "uncordon", http.StatusOK)
+		log.Printf("Node %s uncordoned.", args[0])
+	},
+}
+
+var nodeDrainCmd = &cobra.Command{
+	Use:   "drain <name>",
+	Short: "Move all tasks off a node and cordon it",
+	Long: `Cordon a node, then stop each of its tasks and schedule it on another node.
+Tasks that have not stopped within the timeout are moved anyway. Tasks pinned
+to the node, such as those of task groups, are stopped.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		postNodeAction(manager, args[0], fmt.Sprintf("drain?timeout=%s", timeout), http.StatusAccepted)
+		log.Printf("Draining node %s", args[0])
+
+		url := fmt.Sprintf("http://%s/nodes", manager)
+		deadline := time.Now().Add(timeout + 30*time.Second)
+		for time.Now().Before(deadline) {
+			time.Sleep(2 * time.Second)
+			resp, err := http.Get(url)
+			if err != nil {
+				log.Fatalf("Error connecting to %v: %v", url, err)
+			}
+			var nodes []*node.Node
+			err = json.NewDecoder(resp.Body).Decode(&nodes)
+			resp.Body.Close()
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, n := range nodes {
+				if n.Name == args[0] && n.Scheduling != node.Draining {
+					log.Printf("Node %s drained.", args[0])
+					return
+				}
+			}
+		}
+		log.Fatalf("Node %s is still draining", args[0])
+	},
+}
+
+var nodeLabelCmd = &cobra.Command{
+	Use:   "label <name> <key>=<value>... <key>-...",
+	Short: "Set or remove labels of a node",
+	Long: `Set the labels of a node given as key=value and remove those given as key-.
+Tasks select nodes by their labels with NodeSelector and NodeAffinity.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		changes := make(map[string]*string)
+		for _, arg := range args[1:] {
+			if k, v, ok := strings.Cut(arg, "="); ok && k != "" {
+				changes[k] = &v
+			} else if k, ok := strings.CutSuffix(arg, "-"); ok && k != "" {
+				changes[k] = nil
+			} else {
+				log.Fatalf("Invalid label %q, expected key=value or key-", arg)
+			}
+		}
+		data, _ := json.Marshal(changes)
+
+		url := fmt.Sprintf("http://%s/nodes/%s/labels", manager, args[0])
+		req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Node %s labeled.", args[0])
+	},
+}
+
+var nodeTaintCmd = &cobra.Command{
+	Use:   "taint <name> <key>=<value>:<Effect>... <key>[:<Effect>]-...",
+	Short: "Add or remove taints of a node",
+	Long: `Add taints to a node given as key=value:Effect and remove those given as
+key:Effect- or, for every effect, key-. Effect is NoSchedule, PreferNoSchedule
+or NoExecute. Tasks on the node that do not tolerate a NoExecute taint are
+moved to other nodes.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		for _, arg := range args[1:] {
+			var req *http.Request
+			if spec, ok := strings.CutSuffix(arg, "-"); ok {
+				key, effect, _ := strings.Cut(spec, ":")
+				url := fmt.Sprintf("http://%s/nodes/%s/taints/%s?effect=%s", manager, args[0], key, effect)
+				req, _ = http.NewRequest("DELETE", url, nil)
+			} else {
+				taint, err := node.ParseTaint(arg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				data, _ := json.Marshal(taint)
+				url := fmt.Sprintf("http://%s/nodes/%s/taints", manager, args[0])
+				req, _ = http.NewRequest("POST", url, bytes.NewBuffer(data))
+				req.Header.Set("Content-Type", "application/json")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				log.Fatalf("Error connecting to %v: %v", req.URL, err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				printErrResponse(resp)
+				os.Exit(1)
+			}
+			resp.Body.Close()
+		}
+		log.Printf("Taints of node %s updated.", args[0])
+	},
+}
+
+// postNodeAction posts an action such as cordon to a node and exits unless
+// the manager answers with status.
+func postNodeAction(manager string, name string, action string, status int) {
+	url := fmt.Sprintf("http://%s/nodes/%s/%s", manager, name, action)
+	resp, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		log.Fatalf("Error connecting to %v: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		printErrResponse(resp)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(nodeCmd)
+	nodeCmd.AddCommand(nodeRemoveCmd, nodeCordonCmd, nodeUncordonCmd, nodeDrainCmd, nodeLabelCmd, nodeTaintCmd)
 
-	nodeCmd.Flags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	nodeCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	nodeDrainCmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for tasks to stop before moving them anyway")
 }
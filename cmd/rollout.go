@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// rolloutCmd represents the rollout command
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of a service",
+	Long: `my-orchestrator rollout command.
+
+Changing the template of a service creates a new revision, which the manager
+rolls out by replacing the tasks of the service a few at a time. A rollout
+is paused when a new task fails or does not pass its health check.`,
+}
+
+var rolloutStatusCmd = &cobra.Command{
+	Use:   "status <service>",
+	Short: "Show the progress of the rollout of a service",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/services/%s/rollout", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+
+		var rs task.RolloutStatus
+		err = json.NewDecoder(resp.Body).Decode(&rs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printRollout(args[0], rs)
+	},
+}
+
+var rolloutHistoryCmd = &cobra.Command{
+	Use:   "history <service>",
+	Short: "List the revisions of a service",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/services/%s/revisions", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var revisions []*task.ServiceRevision
+		err = json.NewDecoder(resp.Body).Decode(&revisions)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "REVISION\tCREATED\tIMAGE\tCMD\t")
+		for _, r := range revisions {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%v\t\n", r.Revision, r.CreatedAt.Format(time.RFC3339), r.Template.Image, r.Template.Cmd)
+		}
+		w.Flush()
+	},
+}
+
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo <service>",
+	Short: "Roll a service back to an earlier revision",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		revision, _ := cmd.Flags().GetInt("to-revision")
+
+		url := fmt.Sprintf("http://%s/services/%s/rollout/undo", manager, args[0])
+		if revision > 0 {
+			url = fmt.Sprintf("%s?revision=%d", url, revision)
+		}
+		postRollout(url, args[0])
+	},
+}
+
+var rolloutResumeCmd = &cobra.Command{
+	Use:   "resume <service>",
+	Short: "Resume a paused rollout",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/services/%s/rollout/resume", manager, args[0])
+		postRollout(url, args[0])
+	},
+}
+
+func postRollout(url string, service string) {
+	resp, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		log.Fatalf("Error connecting to %v: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		printErrResponse(resp)
+		os.Exit(1)
+	}
+
+	var rs task.RolloutStatus
+	err = json.NewDecoder(resp.Body).Decode(&rs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printRollout(service, rs)
+}
+
+func printRollout(service string, rs task.RolloutStatus) {
+	fmt.Printf("Service %s revision %d: %s\n", service, rs.Revision, rs.State)
+	fmt.Printf("  %d updated, %d old\n", rs.UpdatedReplicas, rs.OldReplicas)
+	if rs.Message != "" {
+		fmt.Printf("  %s\n", rs.Message)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(rolloutCmd)
+	rolloutCmd.AddCommand(rolloutStatusCmd, rolloutHistoryCmd, rolloutUndoCmd, rolloutResumeCmd)
+
+	rolloutCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	rolloutUndoCmd.Flags().Int("to-revision", 0, "Revision to roll back to, the previous one by default")
+}
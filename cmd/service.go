@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// serviceCmd represents the service command
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage services",
+	Long: `my-orchestrator service command.
+
+A service keeps a number of replicas of a task template running. The
+manager creates or stops tasks until the desired count is reached.`,
+}
+
+var serviceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create or replace a service from a specification file",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Unable to read file: %v", filename)
+		}
+
+		url := fmt.Sprintf("http://%s/services", manager)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Println("Successfully sent service to manager")
+	},
+}
+
+var serviceListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List services",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/services", manager)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var services []*task.Service
+		err = json.NewDecoder(resp.Body).Decode(&services)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "NAME\tREPLICAS\tIMAGE\tREVISION\tROLLOUT\t")
+		for _, s := range services {
+			fmt.Fprintf(w, "%s\t%d/%d\t%s\t%d\t%s\t\n", s.Name, s.RunningReplicas, s.Replicas, s.Template.Image, s.Revision, s.Rollout.State)
+		}
+		w.Flush()
+	},
+}
+
+var serviceScaleCmd = &cobra.Command{
+	Use:   "scale <name> <replicas>",
+	Short: "Change the number of replicas of a service",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		replicas, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Invalid replica count %q", args[1])
+		}
+
+		url := fmt.Sprintf("http://%s/services/%s", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		s := task.Service{}
+		err = json.NewDecoder(resp.Body).Decode(&s)
+		resp.Body.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		s.Replicas = replicas
+		s.RunningReplicas = 0
+		data, _ := json.Marshal(s)
+		req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Service %s scaled to %d replicas", args[0], replicas)
+	},
+}
+
+var serviceRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Stop all tasks of a service and remove it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/services/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Service %s has been removed.", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceCreateCmd, serviceListCmd, serviceScaleCmd, serviceRemoveCmd)
+
+	serviceCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	serviceCreateCmd.Flags().StringP("filename", "f", "service.json", "Service specification file")
+}
@@ -45,7 +45,7 @@ the my-orchestrator manager.`,
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
-		fmt.Fprintln(w, "ID\tName\tCREATED\tSTATE\tCONTAINERNAME\tIMAGE\t")
+		fmt.Fprintln(w, "ID\tName\tCREATED\tSTATE\tREASON\tEXITCODE\tRESTARTS\tCONTAINERNAME\tIMAGE\t")
 
 		for _, task := range tasks {
 			var start string
@@ -56,7 +56,25 @@ the my-orchestrator manager.`,
 			}
 
 			state := task.State.String()[task.State]
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", task.ID, task.Name, start, state, task.Name, task.Image)
+			reason := task.Reason
+			if task.OOMKilled {
+				reason = fmt.Sprintf("%s (OOM)", reason)
+			}
+			if task.StopResult != "" {
+				reason = fmt.Sprintf("%s (%s)", reason, task.StopResult)
+			}
+			if task.NextRestart.After(time.Now()) {
+				reason = fmt.Sprintf("%s (restart in %s)", reason, units.HumanDuration(time.Until(task.NextRestart)))
+			}
+			exitCode := ""
+			if task.Reason != "" {
+				exitCode = fmt.Sprintf("%d", task.ExitCode)
+			}
+			restarts := fmt.Sprintf("%d", task.RestartCount)
+			if n := len(task.RestartHistory); n > 0 {
+				restarts = fmt.Sprintf("%s (%s ago)", restarts, units.HumanDuration(time.Now().UTC().Sub(task.RestartHistory[n-1])))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", task.ID, task.Name, start, state, reason, exitCode, restarts, task.Name, task.Image)
 		}
 		w.Flush()
 	},
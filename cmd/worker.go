@@ -6,9 +6,13 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"net"
+	"os"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/node"
 	"github.com/utsab818/my-orchestrator/worker"
 )
 
@@ -18,19 +22,56 @@ var workerCmd = &cobra.Command{
 	Short: "Worker command to operate a my-orchestrator worker node",
 	Long: `my-orchestrator worker command.
 
-The worker runs tasks and responds to the manager's requests about task state.`,
+The worker runs tasks and responds to the manager's requests about task state.
+With --manager the worker registers itself with the manager and sends it
+heartbeats, so it does not need to be listed in the manager's --workers.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		host, _ := cmd.Flags().GetString("host")
 		port, _ := cmd.Flags().GetInt("port")
 		name, _ := cmd.Flags().GetString("name")
 		dbType, _ := cmd.Flags().GetString("dbtype")
+		runtime, _ := cmd.Flags().GetString("runtime")
+		bindAllowlist, _ := cmd.Flags().GetStringSlice("bind-allowlist")
+		registryAuth, _ := cmd.Flags().GetString("registry-auth")
+		managerAddr, _ := cmd.Flags().GetString("manager")
+		advertise, _ := cmd.Flags().GetString("advertise-address")
+		labels, _ := cmd.Flags().GetStringToString("labels")
+		taintSpecs, _ := cmd.Flags().GetStringSlice("taints")
+		runInterval, _ := cmd.Flags().GetDuration("run-interval")
+		updateInterval, _ := cmd.Flags().GetDuration("update-interval")
+
+		var taints []node.Taint
+		for _, spec := range taintSpecs {
+			t, err := node.ParseTaint(spec)
+			if err != nil {
+				log.Fatal(err)
+			}
+			taints = append(taints, t)
+		}
 
 		log.Println("Starting worker.")
-		w := worker.New(name, dbType)
+		w := worker.New(name, dbType, runtime)
+		w.BindAllowlist = bindAllowlist
+		w.RunInterval = runInterval
+		w.UpdateInterval = updateInterval
+		if registryAuth != "" {
+			auth, err := worker.LoadRegistryAuth(registryAuth)
+			if err != nil {
+				log.Fatalf("unable to load registry credentials: %v", err)
+			}
+			w.RegistryAuth = auth
+		}
 		api := worker.Api{Address: host, Port: port, Worker: w}
 		go w.RunTasks()
 		go w.CollectStats()
 		go w.UpdateTasks()
+		go w.RunProbes()
+		if managerAddr != "" {
+			if advertise == "" {
+				advertise = advertiseAddress(host, port)
+			}
+			go w.Join(managerAddr, advertise, labels, taints)
+		}
 		log.Printf("Starting worker API on http://%s:%d", host, port)
 		api.Start()
 	},
@@ -42,4 +83,26 @@ func init() {
 	workerCmd.Flags().IntP("port", "p", 5556, "Port on which to listen")
 	workerCmd.Flags().StringP("name", "n", fmt.Sprintf("worker-%s", uuid.New().String()), "Name of the worker")
 	workerCmd.Flags().StringP("dbtype", "d", "memory", "Type of datastore to use for tasks (\"memory\" or \"persistent\")")
+	workerCmd.Flags().StringP("runtime", "r", "docker", "Runtime used to run tasks (\"docker\" or \"fake\")")
+	workerCmd.Flags().StringSlice("bind-allowlist", []string{}, "Host directories tasks may bind mount from")
+	workerCmd.Flags().String("registry-auth", "", "JSON file of registry credentials keyed by registry host or secret name")
+	workerCmd.Flags().StringP("manager", "m", "", "Manager to register with, instead of being listed in the manager's --workers")
+	workerCmd.Flags().String("advertise-address", "", "host:port the manager reaches this worker on (defaults to the hostname and --port)")
+	workerCmd.Flags().StringToString("labels", map[string]string{}, "Labels of the node, as key=value pairs")
+	workerCmd.Flags().StringSlice("taints", []string{}, "Taints of the node when registering with --manager, as key=value:Effect")
+	workerCmd.Flags().Duration("run-interval", worker.DefaultRunInterval, "How often queued tasks are started or stopped")
+	workerCmd.Flags().Duration("update-interval", worker.DefaultUpdateInterval, "How often the state of running tasks is checked")
+}
+
+// advertiseAddress returns the address a worker listening on host and port
+// is reachable on from other machines.
+func advertiseAddress(host string, port int) string {
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		h, err := os.Hostname()
+		if err != nil {
+			h = "localhost"
+		}
+		host = h
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
@@ -0,0 +1,165 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// workflowCmd represents the workflow command
+var workflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Manage workflows",
+	Long: `my-orchestrator workflow command.
+
+A workflow is a graph of tasks. Each task starts once the tasks it depends
+on have completed successfully; when a task fails, the tasks depending on it
+are skipped.`,
+}
+
+var workflowCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a workflow from a specification file",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Unable to read file: %v", filename)
+		}
+
+		url := fmt.Sprintf("http://%s/workflows", manager)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Println("Successfully sent workflow to manager")
+	},
+}
+
+var workflowListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List workflows",
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/workflows", manager)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		var workflows []*task.Workflow
+		err = json.NewDecoder(resp.Body).Decode(&workflows)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "NAME\tSUCCEEDED\tSTATE\tAGE\t")
+		for _, wf := range workflows {
+			succeeded := 0
+			for _, wt := range wf.Tasks {
+				if wt.State == task.StepSucceeded {
+					succeeded++
+				}
+			}
+			age := units.HumanDuration(time.Now().UTC().Sub(wf.CreatedAt))
+			fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\t\n", wf.Name, succeeded, len(wf.Tasks), wf.State, age)
+		}
+		w.Flush()
+	},
+}
+
+var workflowStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show the state of every task of a workflow",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+
+		url := fmt.Sprintf("http://%s/workflows/%s", manager, args[0])
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+
+		wf := task.Workflow{}
+		err = json.NewDecoder(resp.Body).Decode(&wf)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Workflow %s: %s\n\n", wf.Name, wf.State)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 5, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "TASK\tDEPENDS ON\tSTATE\tREASON\tTASK ID\t")
+		for _, wt := range wf.Tasks {
+			deps := strings.Join(wt.DependsOn, ",")
+			if deps == "" {
+				deps = "-"
+			}
+			id := "-"
+			if wt.TaskID != uuid.Nil {
+				id = wt.TaskID.String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", wt.Name, deps, wt.State, wt.Reason, id)
+		}
+		w.Flush()
+	},
+}
+
+var workflowRemoveCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Stop the tasks of a workflow and remove it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, _ := cmd.Flags().GetString("manager")
+		url := fmt.Sprintf("http://%s/workflows/%s", manager, args[0])
+		req, _ := http.NewRequest("DELETE", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Error connecting to %v: %v", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			printErrResponse(resp)
+			os.Exit(1)
+		}
+		log.Printf("Workflow %s has been removed.", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workflowCmd)
+	workflowCmd.AddCommand(workflowCreateCmd, workflowListCmd, workflowStatusCmd, workflowRemoveCmd)
+
+	workflowCmd.PersistentFlags().StringP("manager", "m", "localhost:5555", "Manager to talk to")
+	workflowCreateCmd.Flags().StringP("filename", "f", "workflow.json", "Workflow specification file")
+}
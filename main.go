@@ -11,8 +11,23 @@ func main() {
 
 // go run main.go worker
 // go run main.go worker -p 5557
+// go run main.go worker -p 5558 --runtime docker
 // go run main.go manager -w 'localhost:5556,localhost:5557'
 // go run main.go run --filename task1.json
+// go run main.go run --filename task2.json   (exec driver)
 // go run main.go status
 // go run main.go node
+// go run main.go logs -f <id from status>
 // go run main.go stop <id from status>
+// go run main.go job create -f job.json
+// go run main.go job status pi
+// go run main.go cron create -f crontask.json
+// go run main.go workflow create -f workflow.json
+// go run main.go group create -f group.json
+// go run main.go manager -w ''
+// go run main.go worker -p 5559 --manager localhost:5555 --advertise-address localhost:5559 --labels zone=a
+// go run main.go node rm localhost:5559
+// go run main.go node drain localhost:5557 --timeout 2m
+// go run main.go node uncordon localhost:5557
+// go run main.go node label localhost:5556 disk=ssd zone-
+// go run main.go node taint localhost:5557 team=ml:NoSchedule maint-
@@ -21,9 +21,71 @@ func (a *Api) initRouter() {
 		r.Get("/", a.GetTasksHandler)
 		r.Route("/{taskID}", func(r chi.Router) {
 			r.Delete("/", a.StopTaskHandler)
+			r.Get("/logs", a.GetTaskLogsHandler)
+			r.Post("/exec", a.ExecTaskHandler)
+			r.Get("/exec/{execID}", a.InspectExecHandler)
 		})
-		a.Router.Route("/nodes", func(r chi.Router) {
-			r.Get("/", a.GetNodesHandler)
+	})
+	a.Router.Route("/nodes", func(r chi.Router) {
+		r.Get("/", a.GetNodesHandler)
+		r.Post("/", a.RegisterNodeHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Delete("/", a.RemoveNodeHandler)
+			r.Put("/heartbeat", a.HeartbeatHandler)
+			r.Patch("/labels", a.LabelNodeHandler)
+			r.Post("/taints", a.TaintNodeHandler)
+			r.Delete("/taints/{key}", a.UntaintNodeHandler)
+			r.Post("/cordon", a.CordonNodeHandler)
+			r.Post("/uncordon", a.UncordonNodeHandler)
+			r.Post("/drain", a.DrainNodeHandler)
+		})
+	})
+	a.Router.Route("/services", func(r chi.Router) {
+		r.Post("/", a.PutServiceHandler)
+		r.Get("/", a.GetServicesHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Get("/", a.GetServiceHandler)
+			r.Put("/", a.PutServiceHandler)
+			r.Delete("/", a.DeleteServiceHandler)
+			r.Get("/revisions", a.GetRevisionsHandler)
+			r.Route("/rollout", func(r chi.Router) {
+				r.Get("/", a.GetRolloutHandler)
+				r.Post("/undo", a.UndoRolloutHandler)
+				r.Post("/resume", a.ResumeRolloutHandler)
+			})
+		})
+	})
+	a.Router.Route("/jobs", func(r chi.Router) {
+		r.Post("/", a.StartJobHandler)
+		r.Get("/", a.GetJobsHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Get("/", a.GetJobHandler)
+			r.Delete("/", a.DeleteJobHandler)
+		})
+	})
+	a.Router.Route("/crontasks", func(r chi.Router) {
+		r.Post("/", a.PutCronTaskHandler)
+		r.Get("/", a.GetCronTasksHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Get("/", a.GetCronTaskHandler)
+			r.Put("/", a.PutCronTaskHandler)
+			r.Delete("/", a.DeleteCronTaskHandler)
+		})
+	})
+	a.Router.Route("/workflows", func(r chi.Router) {
+		r.Post("/", a.StartWorkflowHandler)
+		r.Get("/", a.GetWorkflowsHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Get("/", a.GetWorkflowHandler)
+			r.Delete("/", a.DeleteWorkflowHandler)
+		})
+	})
+	a.Router.Route("/groups", func(r chi.Router) {
+		r.Post("/", a.StartGroupHandler)
+		r.Get("/", a.GetGroupsHandler)
+		r.Route("/{name}", func(r chi.Router) {
+			r.Get("/", a.GetGroupHandler)
+			r.Delete("/", a.DeleteGroupHandler)
 		})
 	})
 }
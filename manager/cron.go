@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (m *Manager) GetCronTasks() []*task.CronTask {
+	cronTasks, err := m.CronDb.List()
+	if err != nil {
+		log.Printf("error getting list of cron tasks: %v\n", err)
+		return nil
+	}
+	return cronTasks.([]*task.CronTask)
+}
+
+func (m *Manager) GetCronTask(name string) (*task.CronTask, error) {
+	result, err := m.CronDb.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*task.CronTask), nil
+}
+
+// PutCronTask creates or replaces a cron task. The time of the last run is
+// kept, so replacing a cron task does not trigger a run it already made.
+func (m *Manager) PutCronTask(c task.CronTask) error {
+	m.cronMu.Lock()
+	defer m.cronMu.Unlock()
+	existing, err := m.GetCronTask(c.Name)
+	if err == nil {
+		c.CreatedAt = existing.CreatedAt
+		c.LastScheduleTime = existing.LastScheduleTime
+	} else {
+		c.CreatedAt = time.Now().UTC()
+		c.LastScheduleTime = time.Time{}
+	}
+	if c.ConcurrencyPolicy == "" {
+		c.ConcurrencyPolicy = task.ConcurrencyAllow
+	}
+	if c.SuccessfulHistoryLimit == 0 {
+		c.SuccessfulHistoryLimit = 3
+	}
+	if c.FailedHistoryLimit == 0 {
+		c.FailedHistoryLimit = 1
+	}
+	c.NextScheduleTime = time.Time{}
+	if schedule, err := task.ParseCron(c.Schedule); err == nil {
+		if loc, err := c.Location(); err == nil {
+			c.NextScheduleTime = schedule.Next(time.Now().In(loc))
+		}
+	}
+	return m.CronDb.Put(c.Name, &c)
+}
+
+// DeleteCronTask removes a cron task. Tasks it already started keep running.
+func (m *Manager) DeleteCronTask(name string) error {
+	m.cronMu.Lock()
+	defer m.cronMu.Unlock()
+	if _, err := m.GetCronTask(name); err != nil {
+		return err
+	}
+	return m.CronDb.Delete(name)
+}
+
+func (m *Manager) ScheduleCronTasks() {
+	for {
+		log.Println("Checking cron tasks")
+		m.scheduleCronTasks(time.Now())
+		log.Println("Cron task check completed")
+		log.Println("Sleeping for 10 seconds")
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func (m *Manager) scheduleCronTasks(now time.Time) {
+	m.cronMu.Lock()
+	defer m.cronMu.Unlock()
+	for _, c := range m.GetCronTasks() {
+		m.scheduleCronTask(c, now)
+	}
+}
+
+// scheduleCronTask starts a run of c if one became due since the last run.
+// Runs missed while the manager was down are collapsed into a single run.
+// The time of the run is stored before anything else so that a restart
+// does not repeat it.
+func (m *Manager) scheduleCronTask(c *task.CronTask, now time.Time) {
+	schedule, err := task.ParseCron(c.Schedule)
+	if err != nil {
+		log.Printf("Skipping cron task %s: %v\n", c.Name, err)
+		return
+	}
+	loc, err := c.Location()
+	if err != nil {
+		log.Printf("Skipping cron task %s: %v\n", c.Name, err)
+		return
+	}
+
+	last := c.LastScheduleTime
+	if last.IsZero() {
+		last = c.CreatedAt
+	}
+	var due time.Time
+	for next := schedule.Next(last.In(loc)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		due = next
+	}
+
+	c.NextScheduleTime = schedule.Next(now.In(loc))
+	if !due.IsZero() {
+		c.LastScheduleTime = due
+	}
+	err = m.CronDb.Put(c.Name, c)
+	if err != nil {
+		log.Printf("Error storing cron task %s: %v\n", c.Name, err)
+		return
+	}
+
+	if !due.IsZero() {
+		m.runCronTask(c, due)
+	}
+	m.pruneCronHistory(c)
+}
+
+func (m *Manager) runCronTask(c *task.CronTask, due time.Time) {
+	owner := task.Owner{Kind: task.OwnerCron, Name: c.Name}
+	var active []*task.Task
+	for _, t := range m.ownedTasks(owner) {
+		if t.Active() {
+			active = append(active, t)
+		}
+	}
+
+	if len(active) > 0 {
+		switch c.ConcurrencyPolicy {
+		case task.ConcurrencyForbid:
+			log.Printf("Skipping run of cron task %s due at %v, %d tasks still active\n", c.Name, due, len(active))
+			return
+		case task.ConcurrencyReplace:
+			log.Printf("Replacing %d active tasks of cron task %s\n", len(active), c.Name)
+			for _, t := range active {
+				m.requestStop(t)
+			}
+		}
+	}
+
+	log.Printf("Starting run of cron task %s due at %v\n", c.Name, due)
+	m.createOwnedTask(owner, c.Template)
+}
+
+// pruneCronHistory removes the oldest finished tasks of c beyond its
+// history limits from the manager.
+func (m *Manager) pruneCronHistory(c *task.CronTask) {
+	var completed, failed []*task.Task
+	for _, t := range m.ownedTasks(task.Owner{Kind: task.OwnerCron, Name: c.Name}) {
+		switch t.State {
+		case task.Completed:
+			completed = append(completed, t)
+		case task.Failed:
+			failed = append(failed, t)
+		}
+	}
+
+	prune := func(tasks []*task.Task, limit int) {
+		if len(tasks) <= limit {
+			return
+		}
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].FinishTime.After(tasks[j].FinishTime)
+		})
+		for _, t := range tasks[limit:] {
+			log.Printf("Removing task %s from the history of cron task %s\n", t.ID, c.Name)
+			m.TaskDb.Delete(t.ID.String())
+		}
+	}
+	prune(completed, c.SuccessfulHistoryLimit)
+	prune(failed, c.FailedHistoryLimit)
+}
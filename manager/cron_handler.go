@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (a *Api) PutCronTaskHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	c := task.CronTask{}
+	err := d.Decode(&c)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	if name := chi.URLParam(r, "name"); name != "" {
+		c.Name = name
+	}
+
+	err = task.ValidateCronTask(c)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid cron task %s: %v", c.Name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.PutCronTask(c)
+	if err != nil {
+		msg := fmt.Sprintf("Error storing cron task %s: %v", c.Name, err)
+		log.Println(msg)
+		w.WriteHeader(500)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 500, Message: msg})
+		return
+	}
+
+	stored, err := a.Manager.GetCronTask(c.Name)
+	if err != nil {
+		stored = &c
+	}
+	log.Printf("Stored cron task %s with schedule %q\n", stored.Name, stored.Schedule)
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(stored)
+}
+
+func (a *Api) GetCronTasksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetCronTasks())
+}
+
+func (a *Api) GetCronTaskHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	c, err := a.Manager.GetCronTask(name)
+	if err != nil {
+		log.Printf("No cron task with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(c)
+}
+
+func (a *Api) DeleteCronTaskHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.DeleteCronTask(name)
+	if err != nil {
+		log.Printf("No cron task with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	log.Printf("Deleted cron task %s\n", name)
+	w.WriteHeader(204)
+}
@@ -0,0 +1,170 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// DefaultDrainTimeout is how long a drain waits for the tasks of a node to
+// stop before moving them anyway.
+const DefaultDrainTimeout = 5 * time.Minute
+
+// CordonNode stops new tasks from being scheduled on a node. Its running
+// tasks are left alone.
+func (m *Manager) CordonNode(name string) (*node.Node, error) {
+	n := m.updateNode(name, func(n *node.Node) {
+		if n.Scheduling == node.Schedulable {
+			n.Scheduling = node.Cordoned
+			log.Printf("Cordoned node %s\n", name)
+		}
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	return n, nil
+}
+
+// UncordonNode lets new tasks be scheduled on a node again.
+func (m *Manager) UncordonNode(name string) (*node.Node, error) {
+	var err error
+	n := m.updateNode(name, func(n *node.Node) {
+		if n.Scheduling == node.Draining {
+			err = fmt.Errorf("node %s is being drained", name)
+			return
+		}
+		n.Scheduling = node.Schedulable
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Uncordoned node %s\n", name)
+	return n, nil
+}
+
+// DrainNode cordons a node and moves its tasks to other nodes in the
+// background. The node stays cordoned once it has been drained.
+func (m *Manager) DrainNode(name string, timeout time.Duration) (*node.Node, error) {
+	var err error
+	n := m.updateNode(name, func(n *node.Node) {
+		if n.Scheduling == node.Draining {
+			err = fmt.Errorf("node %s is already being drained", name)
+			return
+		}
+		n.Scheduling = node.Draining
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Draining node %s with a timeout of %v\n", name, timeout)
+	go m.drainNode(n, timeout)
+	return n, nil
+}
+
+// drainNode asks the worker to stop every task of the node and schedules
+// each task again once the worker has stopped it, or once the timeout has
+// passed. Until then the tasks keep counting as running, so their owners do
+// not replace them. Tasks pinned to the node cannot move and are stopped.
+func (m *Manager) drainNode(n *node.Node, timeout time.Duration) {
+	moving := make(map[uuid.UUID]*task.Task)
+	for _, t := range m.GetTasks() {
+		if w, _ := m.taskWorker(t.ID); w != n.Name || !t.Active() || t.State == task.Pending {
+			continue
+		}
+		if t.Node != "" {
+			log.Printf("Stopping task %s, it is pinned to node %s\n", t.ID, n.Name)
+			m.requestStop(t)
+			continue
+		}
+		t.NextRestart = time.Now().UTC()
+		m.TaskDb.Put(t.ID.String(), t)
+		m.stopTask(n.Name, t.ID.String())
+		moving[t.ID] = t
+	}
+	m.moveWhenStopped(n.Name, moving, timeout, task.ReasonNodeDrained)
+
+	m.updateNode(n.Name, func(n *node.Node) {
+		if n.Scheduling == node.Draining {
+			n.Scheduling = node.Cordoned
+		}
+	})
+	log.Printf("Drained node %s\n", n.Name)
+}
+
+// moveWhenStopped schedules each of the tasks the worker was asked to stop
+// again once it has stopped them, or once the timeout has passed, and only
+// then releases their resources on the node.
+func (m *Manager) moveWhenStopped(worker string, tasks map[uuid.UUID]*task.Task, timeout time.Duration, reason string) {
+	deadline := time.Now().Add(timeout)
+	for len(tasks) > 0 && time.Now().Before(deadline) {
+		time.Sleep(2 * time.Second)
+		running, err := m.workerActiveTasks(worker)
+		if err != nil {
+			log.Printf("Error checking tasks of node %s: %v\n", worker, err)
+			continue
+		}
+		for id, t := range tasks {
+			if !running[id] {
+				m.moveStoppedTask(worker, t, reason)
+				delete(tasks, id)
+			}
+		}
+	}
+	for _, t := range tasks {
+		log.Printf("Task %s did not stop on node %s within %v, moving it anyway\n", t.ID, worker, timeout)
+		m.moveStoppedTask(worker, t, reason)
+	}
+}
+
+func (m *Manager) moveStoppedTask(worker string, t *task.Task, reason string) {
+	if result, err := m.TaskDb.Get(t.ID.String()); err == nil {
+		t = result.(*task.Task)
+	}
+	// The task may have been moved already, as when the node was lost.
+	if w, _ := m.taskWorker(t.ID); w != worker {
+		return
+	}
+	if t.StopRequested {
+		m.unassignTask(worker, t.ID)
+		m.release(worker, t)
+		t.State = task.Completed
+		t.Reason = task.ReasonStopped
+		t.FinishTime = time.Now().UTC()
+		m.TaskDb.Put(t.ID.String(), t)
+		return
+	}
+	m.resubmitTask(worker, t, reason)
+	log.Printf("Moving task %s off node %s (%s)\n", t.ID, worker, reason)
+}
+
+// workerActiveTasks returns the IDs of the tasks a worker has not finished.
+func (m *Manager) workerActiveTasks(worker string) (map[uuid.UUID]bool, error) {
+	resp, err := workerClient.Get(fmt.Sprintf("http://%s/tasks", worker))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var tasks []*task.Task
+	err = json.NewDecoder(resp.Body).Decode(&tasks)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[uuid.UUID]bool)
+	for _, t := range tasks {
+		if t.State == task.Scheduled || t.State == task.Running {
+			active[t.ID] = true
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,353 @@
+package manager_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/manager"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+	"github.com/utsab818/my-orchestrator/worker"
+)
+
+const loopInterval = 100 * time.Millisecond
+
+// nodeLostAfter is how long the manager of a cluster waits for a worker it
+// cannot reach before moving its tasks.
+const nodeLostAfter = time.Second
+
+// cluster is a manager and workers on the fake runtime, reached over HTTP
+// like the CLI reaches them. The manager reaches each worker through a
+// proxy, so a worker can be made unreachable by stopping its proxy.
+type cluster struct {
+	manager string
+	workers []*worker.Worker
+	proxies []*httptest.Server
+}
+
+func newCluster(t *testing.T, workers int) *cluster {
+	t.Helper()
+	c := &cluster{}
+	var addrs []string
+	for i := 0; i < workers; i++ {
+		addr := freeAddr(t)
+		w := worker.New(fmt.Sprintf("worker-%d", i), "memory", "fake")
+		w.RunInterval = loopInterval
+		w.UpdateInterval = loopInterval
+		go w.RunTasks()
+		go w.UpdateTasks()
+		go w.RunProbes()
+		go (&worker.Api{Address: "127.0.0.1", Port: port(addr), Worker: w}).Start()
+
+		proxy := httptest.NewServer(httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: addr}))
+		t.Cleanup(proxy.Close)
+		c.workers = append(c.workers, w)
+		c.proxies = append(c.proxies, proxy)
+		addrs = append(addrs, proxy.Listener.Addr().String())
+	}
+
+	c.manager = freeAddr(t)
+	m := manager.New(addrs, "roundrobin", "memory")
+	m.ProcessInterval = loopInterval
+	m.UpdateInterval = loopInterval
+	m.RestartInterval = loopInterval
+	m.ReconcileInterval = loopInterval
+	m.NodeLostAfter = nodeLostAfter
+	go m.ProcessTasks()
+	go m.UpdateTasks()
+	go m.RestartTasks()
+	go m.ReconcileServices()
+	go m.ReconcileJobs()
+	go m.ScheduleCronTasks()
+	go m.ReconcileWorkflows()
+	go m.ReconcileGroups()
+	go (&manager.Api{Address: "127.0.0.1", Port: port(c.manager), Manager: m}).Start()
+
+	// Tasks sent before the nodes have been reached would be held back.
+	waitFor(t, "nodes to be ready", func() bool {
+		var nodes []node.Node
+		if c.get("/nodes", &nodes) != nil || len(nodes) != workers {
+			return false
+		}
+		for _, n := range nodes {
+			if n.State != node.Ready {
+				return false
+			}
+		}
+		return true
+	})
+	return c
+}
+
+// setBehaviour sets the behaviour of containers started from image on
+// every worker.
+func (c *cluster) setBehaviour(image string, b task.FakeBehaviour) {
+	for _, w := range c.workers {
+		w.Runtime.(*task.Fake).SetBehaviour(image, b)
+	}
+}
+
+// stopWorker makes a worker unreachable for the manager. The tasks it runs
+// keep running, as on a node cut off from the network.
+func (c *cluster) stopWorker(i int) {
+	c.proxies[i].CloseClientConnections()
+	c.proxies[i].Close()
+}
+
+// runningOn returns the index of the worker running a task, or -1.
+func (c *cluster) runningOn(id uuid.UUID) int {
+	for i, w := range c.workers {
+		for _, t := range w.GetTasks() {
+			if t.ID == id && t.State == task.Running {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// crash makes the container of a task exit with exitCode on whichever
+// worker runs it.
+func (c *cluster) crash(t *testing.T, containerID string, exitCode int) {
+	t.Helper()
+	for _, w := range c.workers {
+		if w.Runtime.(*task.Fake).Crash(containerID, exitCode) == nil {
+			return
+		}
+	}
+	t.Fatalf("no worker runs container %s", containerID)
+}
+
+func (c *cluster) get(path string, v any) error {
+	resp, err := http.Get("http://" + c.manager + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *cluster) run(t *testing.T, tk task.Task) {
+	t.Helper()
+	te := task.TaskEvent{ID: uuid.New(), State: task.Running, Timestamp: time.Now(), Task: tk}
+	data, _ := json.Marshal(te)
+	resp, err := http.Post("http://"+c.manager+"/tasks", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /tasks: %s", resp.Status)
+	}
+}
+
+func (c *cluster) task(id uuid.UUID) *task.Task {
+	var tasks []*task.Task
+	if c.get("/tasks", &tasks) != nil {
+		return nil
+	}
+	for _, t := range tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// waitForTask waits until the task is in state and returns it.
+func (c *cluster) waitForTask(t *testing.T, id uuid.UUID, state task.State) *task.Task {
+	t.Helper()
+	var tk *task.Task
+	waitFor(t, fmt.Sprintf("task to be %v", state), func() bool {
+		tk = c.task(id)
+		return tk != nil && tk.State == state
+	})
+	return tk
+}
+
+func TestRunAndStopTask(t *testing.T) {
+	c := newCluster(t, 2)
+	c.setBehaviour("web", task.FakeBehaviour{Output: "hello\n"})
+
+	tk := task.Task{ID: uuid.New(), Name: "web", State: task.Scheduled, Image: "web"}
+	c.run(t, tk)
+	running := c.waitForTask(t, tk.ID, task.Running)
+	if running.ContainerId == "" {
+		t.Errorf("running task has no container")
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/tasks/%s/logs", c.manager, tk.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(logs) != "hello\n" {
+		t.Errorf("logs = %q, want %q", logs, "hello\n")
+	}
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("http://%s/tasks/%s", c.manager, tk.ID), nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	stopped := c.waitForTask(t, tk.ID, task.Completed)
+	if stopped.Reason != task.ReasonStopped {
+		t.Errorf("reason = %q, want %q", stopped.Reason, task.ReasonStopped)
+	}
+}
+
+func TestTasksAreSpreadOverWorkers(t *testing.T) {
+	c := newCluster(t, 2)
+
+	var ids []uuid.UUID
+	for i := 0; i < 4; i++ {
+		tk := task.Task{ID: uuid.New(), Name: fmt.Sprintf("web-%d", i), State: task.Scheduled, Image: "web"}
+		c.run(t, tk)
+		ids = append(ids, tk.ID)
+	}
+	for _, id := range ids {
+		c.waitForTask(t, id, task.Running)
+	}
+
+	for i, w := range c.workers {
+		if n := len(w.GetTasks()); n != 2 {
+			t.Errorf("worker %d runs %d tasks, want 2", i, n)
+		}
+	}
+}
+
+func TestFailedTaskIsReported(t *testing.T) {
+	c := newCluster(t, 1)
+	c.setBehaviour("crash", task.FakeBehaviour{RunFor: 200 * time.Millisecond, ExitCode: 3})
+
+	tk := task.Task{ID: uuid.New(), Name: "crash", State: task.Scheduled, Image: "crash"}
+	c.run(t, tk)
+	failed := c.waitForTask(t, tk.ID, task.Failed)
+	if failed.ExitCode != 3 || failed.Reason != task.ReasonFailed {
+		t.Errorf("exit code %d, reason %q, want 3, %q", failed.ExitCode, failed.Reason, task.ReasonFailed)
+	}
+}
+
+func TestCrashedTaskIsRestartedAfterBackoff(t *testing.T) {
+	c := newCluster(t, 1)
+
+	tk := task.Task{ID: uuid.New(), Name: "web", State: task.Scheduled, Image: "web",
+		RestartPolicy: task.RestartOnFailure, RestartBackoff: &task.RestartBackoff{Initial: 1, Max: 1}}
+	c.run(t, tk)
+	first := c.waitForTask(t, tk.ID, task.Running)
+
+	c.crash(t, first.ContainerId, 1)
+	crashed := time.Now()
+	waitFor(t, "task to back off", func() bool {
+		tk := c.task(tk.ID)
+		return tk != nil && tk.State == task.Pending && tk.Reason == task.ReasonCrashLoopBackOff
+	})
+
+	var restarted *task.Task
+	waitFor(t, "task to be restarted", func() bool {
+		restarted = c.task(tk.ID)
+		return restarted != nil && restarted.State == task.Running && restarted.ContainerId != first.ContainerId
+	})
+	if waited := time.Since(crashed); waited < time.Second {
+		t.Errorf("task was restarted after %v, before its backoff of 1s", waited)
+	}
+	if restarted.RestartCount != 1 {
+		t.Errorf("restart count = %d, want 1", restarted.RestartCount)
+	}
+}
+
+func TestFailingLivenessProbeFailsTask(t *testing.T) {
+	c := newCluster(t, 1)
+	c.setBehaviour("sick", task.FakeBehaviour{HealthStatus: http.StatusInternalServerError})
+
+	tk := task.Task{ID: uuid.New(), Name: "sick", State: task.Scheduled, Image: "sick",
+		ExposedPorts: nat.PortSet{"80/tcp": {}},
+		LivenessProbe: &task.Probe{HTTPGet: &task.HTTPGetHook{Path: "/", Port: "80/tcp"},
+			Period: 1, FailureThreshold: 1}}
+	c.run(t, tk)
+	failed := c.waitForTask(t, tk.ID, task.Failed)
+	if failed.Reason != task.ReasonLivenessProbeFailed {
+		t.Errorf("reason = %q, want %q", failed.Reason, task.ReasonLivenessProbeFailed)
+	}
+}
+
+func TestTaskOfLostWorkerIsRescheduled(t *testing.T) {
+	c := newCluster(t, 2)
+
+	tk := task.Task{ID: uuid.New(), Name: "web", State: task.Scheduled, Image: "web"}
+	c.run(t, tk)
+	c.waitForTask(t, tk.ID, task.Running)
+	lost := c.runningOn(tk.ID)
+	if lost < 0 {
+		t.Fatal("no worker runs the task")
+	}
+
+	c.stopWorker(lost)
+	waitFor(t, "node to be lost", func() bool {
+		var nodes []node.Node
+		if c.get("/nodes", &nodes) != nil {
+			return false
+		}
+		for _, n := range nodes {
+			if n.Name == c.proxies[lost].Listener.Addr().String() {
+				return n.State == node.Lost
+			}
+		}
+		return false
+	})
+	other := 1 - lost
+	waitFor(t, "task to run on the other worker", func() bool {
+		for _, wt := range c.workers[other].GetTasks() {
+			if wt.ID == tk.ID && wt.State == task.Running {
+				return true
+			}
+		}
+		return false
+	})
+	moved := c.waitForTask(t, tk.ID, task.Running)
+	if moved.RestartCount != 1 {
+		t.Errorf("restart count = %d, want 1", moved.RestartCount)
+	}
+}
+
+// freeAddr returns a loopback address with a port nothing listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func port(addr string) int {
+	_, p, _ := net.SplitHostPort(addr)
+	n, _ := strconv.Atoi(p)
+	return n
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,311 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (m *Manager) GetGroups() []*task.TaskGroup {
+	groups, err := m.GroupDb.List()
+	if err != nil {
+		log.Printf("error getting list of task groups: %v\n", err)
+		return nil
+	}
+	return groups.([]*task.TaskGroup)
+}
+
+func (m *Manager) GetGroup(name string) (*task.TaskGroup, error) {
+	result, err := m.GroupDb.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*task.TaskGroup), nil
+}
+
+// AddGroup stores a new task group, which is placed on a node by the next
+// reconciliation.
+func (m *Manager) AddGroup(g task.TaskGroup) error {
+	m.groupMu.Lock()
+	defer m.groupMu.Unlock()
+	if _, err := m.GetGroup(g.Name); err == nil {
+		return fmt.Errorf("task group %s already exists", g.Name)
+	}
+	g.Node = ""
+	g.State = task.GroupPending
+	g.Reason = ""
+	g.InitTaskIDs = nil
+	g.TaskIDs = nil
+	g.CreatedAt = time.Now().UTC()
+	return m.GroupDb.Put(g.Name, &g)
+}
+
+// DeleteGroup stops every task of a group and removes it.
+func (m *Manager) DeleteGroup(name string) error {
+	m.groupMu.Lock()
+	defer m.groupMu.Unlock()
+	g, err := m.GetGroup(name)
+	if err != nil {
+		return err
+	}
+	m.stopGroup(g)
+	m.releaseGroup(g)
+	return m.GroupDb.Delete(name)
+}
+
+func (m *Manager) ReconcileGroups() {
+	for {
+		log.Println("Reconciling task groups")
+		m.reconcileGroups()
+		log.Println("Task group reconciliation completed")
+		log.Printf("Sleeping for %v\n", m.ReconcileInterval)
+		time.Sleep(m.ReconcileInterval)
+	}
+}
+
+func (m *Manager) reconcileGroups() {
+	m.groupMu.Lock()
+	defer m.groupMu.Unlock()
+	for _, g := range m.GetGroups() {
+		if !g.Finished() {
+			m.reconcileGroup(g)
+		}
+	}
+}
+
+// reconcileGroup moves a group through its states: it picks a node for
+// the whole group and reserves the group's resources on it, runs the init
+// tasks one at a time, starts the tasks and finally stops all of them once
+// one has exited.
+func (m *Manager) reconcileGroup(g *task.TaskGroup) {
+	if g.State == task.GroupPending {
+		n, err := m.SelectWorker(g.Resources())
+		if err != nil {
+			log.Printf("Unable to place task group %s: %v\n", g.Name, err)
+			return
+		}
+		g.Node = n.Name
+		g.State = task.GroupInitializing
+		m.reserveGroup(g)
+		log.Printf("Placed task group %s on %s\n", g.Name, g.Node)
+	}
+
+	if g.State == task.GroupInitializing {
+		if !m.runGroupInitTasks(g) || !m.startGroupTasks(g) {
+			if g.Finished() {
+				m.releaseGroup(g)
+			}
+			m.GroupDb.Put(g.Name, g)
+			return
+		}
+		g.State = task.GroupRunning
+	}
+
+	for _, id := range g.TaskIDs {
+		t := m.groupTask(id)
+		if t == nil || (t.State != task.Completed && t.State != task.Failed) {
+			continue
+		}
+		g.State = task.GroupCompleted
+		if t.State == task.Failed {
+			g.State = task.GroupFailed
+		}
+		g.Reason = fmt.Sprintf("task %s %s", t.Name, t.Reason)
+		g.CompletionTime = time.Now().UTC()
+		log.Printf("Stopping task group %s: %s\n", g.Name, g.Reason)
+		m.stopGroup(g)
+		break
+	}
+	if g.Finished() || m.groupScheduled(g) {
+		m.releaseGroup(g)
+	}
+	m.GroupDb.Put(g.Name, g)
+}
+
+// runGroupInitTasks starts the next init task once the previous one has
+// completed and reports whether all of them have. A failed init task fails
+// the group.
+func (m *Manager) runGroupInitTasks(g *task.TaskGroup) bool {
+	for i, tmpl := range g.InitTasks {
+		if i == len(g.InitTaskIDs) {
+			t := m.createGroupTask(g, tmpl, false)
+			g.InitTaskIDs = append(g.InitTaskIDs, t.ID)
+			return false
+		}
+
+		t := m.groupTask(g.InitTaskIDs[i])
+		switch {
+		case t == nil || t.Active():
+			return false
+		case t.State == task.Completed && t.Reason == task.ReasonCompleted:
+			continue
+		default:
+			g.State = task.GroupFailed
+			g.Reason = fmt.Sprintf("init task %s %s", t.Name, t.Reason)
+			g.CompletionTime = time.Now().UTC()
+			log.Printf("Task group %s failed: %s\n", g.Name, g.Reason)
+			return false
+		}
+	}
+	return true
+}
+
+// startGroupTasks queues the first task of a group and, once it is
+// running, the others, and reports whether all of them have been queued.
+// The first task owns the network namespace the others join, so it
+// publishes the ports of all of them, and the others take its priority.
+func (m *Manager) startGroupTasks(g *task.TaskGroup) bool {
+	if len(g.TaskIDs) > 0 {
+		return m.startGroupSidecars(g)
+	}
+
+	leader := g.Tasks[0]
+	leader.ExposedPorts = nat.PortSet{}
+	leader.PortBindings = make(map[string]string)
+	for _, t := range g.Tasks {
+		for p := range t.ExposedPorts {
+			leader.ExposedPorts[p] = struct{}{}
+		}
+		for k, v := range t.PortBindings {
+			leader.PortBindings[k] = v
+		}
+	}
+
+	lt := m.createGroupTask(g, leader, true)
+	g.TaskIDs = append(g.TaskIDs, lt.ID)
+	return len(g.Tasks) == 1
+}
+
+// startGroupSidecars queues the tasks that join the network namespace of
+// the first task of a group once that task is running, as their containers
+// cannot be created before it. A first task that has already exited leaves
+// the group to be stopped.
+func (m *Manager) startGroupSidecars(g *task.TaskGroup) bool {
+	lt := m.groupTask(g.TaskIDs[0])
+	switch {
+	case lt == nil || (lt.Active() && lt.State != task.Running):
+		return false
+	case lt.State != task.Running:
+		return true
+	}
+	for _, tmpl := range g.Tasks[1:] {
+		tmpl.NetworkMode = fmt.Sprintf("container:%s", lt.Name)
+		tmpl.ExposedPorts = nil
+		tmpl.PortBindings = nil
+		tmpl.Priority = lt.Priority
+		t := m.createGroupTask(g, tmpl, false)
+		g.TaskIDs = append(g.TaskIDs, t.ID)
+	}
+	log.Printf("Started the tasks of task group %s after %s\n", g.Name, lt.Name)
+	return true
+}
+
+// reserveGroup allocates the resources of a whole group on its node, so
+// other tasks cannot take the room while its init tasks run or before all
+// of its tasks have been scheduled. Only tasks of the group may use the
+// reservation.
+func (m *Manager) reserveGroup(g *task.TaskGroup) {
+	r := g.Resources()
+	r.ID = uuid.New()
+	m.nodeMu.Lock()
+	m.groupReservations[g.Name] = r
+	m.nodeMu.Unlock()
+	m.allocate(g.Node, &r)
+}
+
+// releaseGroup returns the resources reserved for a group to its node. The
+// host ports of tasks of the group that have been sent to the node stay
+// allocated to them.
+func (m *Manager) releaseGroup(g *task.TaskGroup) {
+	m.nodeMu.Lock()
+	r, ok := m.groupReservations[g.Name]
+	delete(m.groupReservations, g.Name)
+	m.nodeMu.Unlock()
+	if !ok {
+		return
+	}
+	var held []string
+	for _, id := range slices.Concat(g.InitTaskIDs, g.TaskIDs) {
+		if w, _ := m.taskWorker(id); w != g.Node {
+			continue
+		}
+		if t := m.groupTask(id); t != nil {
+			held = append(held, t.BoundHostPorts()...)
+		}
+	}
+	m.updateNode(g.Node, func(n *node.Node) {
+		releaseFrom(n, &r)
+		for _, p := range held {
+			n.HostPortsAllocated[p] = true
+		}
+	})
+}
+
+// groupReservation returns the resources reserved for the group that owns
+// a task, if any.
+func (m *Manager) groupReservation(owner task.Owner) (task.Task, bool) {
+	if owner.Kind != task.OwnerGroup {
+		return task.Task{}, false
+	}
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	r, ok := m.groupReservations[owner.Name]
+	return r, ok
+}
+
+// groupScheduled reports whether every task of a group has been sent to
+// its node, so it holds its own resources.
+func (m *Manager) groupScheduled(g *task.TaskGroup) bool {
+	if g.State != task.GroupRunning {
+		return false
+	}
+	for _, id := range g.TaskIDs {
+		if t := m.groupTask(id); t != nil && t.State == task.Pending {
+			return false
+		}
+	}
+	return true
+}
+
+// createGroupTask queues a task of a group pinned to the group's node with
+// the group volumes mounted. Only the owner of the volumes, the first of
+// the tasks, accounts for their size and applies their policy.
+func (m *Manager) createGroupTask(g *task.TaskGroup, tmpl task.Task, ownsVolumes bool) task.Task {
+	tmpl.Node = g.Node
+	tmpl.Volumes = append([]task.Volume{}, tmpl.Volumes...)
+	for _, v := range g.Volumes {
+		if !ownsVolumes {
+			v.Size = 0
+			v.Policy = task.VolumeRetain
+		}
+		tmpl.Volumes = append(tmpl.Volumes, v)
+	}
+	return m.createOwnedTask(task.Owner{Kind: task.OwnerGroup, Name: g.Name}, tmpl)
+}
+
+// stopGroup stops the active tasks of a group in the reverse order they
+// were started.
+func (m *Manager) stopGroup(g *task.TaskGroup) {
+	ids := append(append([]uuid.UUID{}, g.InitTaskIDs...), g.TaskIDs...)
+	for i := len(ids) - 1; i >= 0; i-- {
+		t := m.groupTask(ids[i])
+		if t != nil && t.Active() {
+			m.requestStop(t)
+		}
+	}
+}
+
+func (m *Manager) groupTask(id uuid.UUID) *task.Task {
+	result, err := m.TaskDb.Get(id.String())
+	if err != nil {
+		log.Printf("Task %s of a task group is missing: %v\n", id, err)
+		return nil
+	}
+	return result.(*task.Task)
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (a *Api) StartGroupHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	g := task.TaskGroup{}
+	err := d.Decode(&g)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = task.ValidateTaskGroup(g)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid task group %s: %v", g.Name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.AddGroup(g)
+	if err != nil {
+		msg := fmt.Sprintf("Error adding task group %s: %v", g.Name, err)
+		log.Println(msg)
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 409, Message: msg})
+		return
+	}
+
+	stored, err := a.Manager.GetGroup(g.Name)
+	if err != nil {
+		stored = &g
+	}
+	log.Printf("Added task group %s with %d tasks\n", stored.Name, len(stored.Tasks))
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(stored)
+}
+
+func (a *Api) GetGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetGroups())
+}
+
+func (a *Api) GetGroupHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	g, err := a.Manager.GetGroup(name)
+	if err != nil {
+		log.Printf("No task group with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(g)
+}
+
+func (a *Api) DeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.DeleteGroup(name)
+	if err != nil {
+		log.Printf("No task group with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	log.Printf("Deleted task group %s\n", name)
+	w.WriteHeader(204)
+}
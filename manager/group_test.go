@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func TestGroupReservation(t *testing.T) {
+	m := newPreemptManager(t, "a")
+	g := &task.TaskGroup{
+		Name: "pod",
+		Node: "a",
+		Tasks: []task.Task{
+			{Cpu: 1, PortBindings: map[string]string{"80/tcp": "8080"}},
+			{Cpu: 1},
+		},
+	}
+	m.reserveGroup(g)
+
+	other := task.Task{ID: uuid.New(), Cpu: 1}
+	if _, err := m.SelectWorker(other); err == nil {
+		t.Errorf("a task outside the group was placed in the room reserved for it")
+	}
+
+	leader := task.Task{ID: uuid.New(), Cpu: 1, Node: "a", Owner: task.Owner{Kind: task.OwnerGroup, Name: "pod"},
+		PortBindings: map[string]string{"80/tcp": "8080"}}
+	if _, err := m.placeTask(leader); err != nil {
+		t.Fatalf("placing a task of the group: %v", err)
+	}
+	m.TaskDb.Put(leader.ID.String(), &leader)
+	m.assignTask("a", leader.ID)
+	m.allocate("a", &leader)
+	g.TaskIDs = []uuid.UUID{leader.ID}
+
+	m.releaseGroup(g)
+	n := m.getNode("a")
+	if n.CpuAllocated != 1 {
+		t.Errorf("%v cores allocated after releasing the group, want 1", n.CpuAllocated)
+	}
+	if !n.HostPortsAllocated["8080/tcp"] {
+		t.Errorf("host port of the running task was released with the group: %v", n.HostPortsAllocated)
+	}
+	if _, ok := m.groupReservation(leader.Owner); ok {
+		t.Errorf("group is still reserved after being released")
+	}
+}
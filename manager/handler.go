@@ -1,15 +1,20 @@
 package manager
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	"github.com/utsab818/my-orchestrator/task"
+	"github.com/utsab818/my-orchestrator/utils"
 )
 
 type ErrResponse struct {
@@ -36,6 +41,19 @@ func (a *Api) StartTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = task.Validate(te.Task)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid task %v: %v", te.Task.ID, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		e := ErrResponse{
+			HTTPStatusCode: 400,
+			Message:        msg,
+		}
+		json.NewEncoder(w).Encode(e)
+		return
+	}
+
 	a.Manager.AddTask(te)
 	log.Printf("Added task %v\n", te.Task.ID)
 	w.WriteHeader(201)
@@ -81,5 +99,171 @@ func (a *Api) StopTaskHandler(w http.ResponseWriter, r *http.Request) {
 func (a *Api) GetNodesHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
-	json.NewEncoder(w).Encode(a.Manager.WorkerNodes)
+	json.NewEncoder(w).Encode(a.Manager.nodes())
+}
+
+// GetTaskLogsHandler proxies a log request to the worker running the task,
+// passing the query string through and streaming the response back.
+func (a *Api) GetTaskLogsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	tID, err := uuid.Parse(taskID)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid taskID %q: %v", taskID, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	taskWorker, ok := a.Manager.lastTaskWorker(tID)
+	if !ok {
+		msg := fmt.Sprintf("No worker found for task %v", tID)
+		log.Println(msg)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: msg})
+		return
+	}
+
+	url := fmt.Sprintf("http://%s/tasks/%s/logs?%s", taskWorker, tID, r.URL.RawQuery)
+	req, err := http.NewRequestWithContext(r.Context(), "GET", url, nil)
+	if err != nil {
+		log.Printf("error creating request for logs of task %s: %v\n", tID, err)
+		w.WriteHeader(500)
+		return
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		msg := fmt.Sprintf("Error connecting to %v: %v", taskWorker, err)
+		log.Println(msg)
+		w.WriteHeader(502)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 502, Message: msg})
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 4096)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			w.Write(buf[:n])
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ExecTaskHandler forwards an exec request to the worker running the task.
+// Once the worker has switched protocols the client connection is hijacked
+// as well and bytes are relayed in both directions.
+func (a *Api) ExecTaskHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	tID, _ := uuid.Parse(taskID)
+	taskWorker, ok := a.Manager.lastTaskWorker(tID)
+	if !ok {
+		msg := fmt.Sprintf("No worker found for task %v", taskID)
+		log.Println(msg)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: msg})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: err.Error()})
+		return
+	}
+
+	workerConn, err := net.Dial("tcp", taskWorker)
+	if err != nil {
+		msg := fmt.Sprintf("Error connecting to %v: %v", taskWorker, err)
+		log.Println(msg)
+		w.WriteHeader(502)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 502, Message: msg})
+		return
+	}
+
+	url := fmt.Sprintf("http://%s/tasks/%s/exec", taskWorker, tID)
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+	err = req.Write(workerConn)
+	if err != nil {
+		workerConn.Close()
+		log.Printf("Error sending exec request to %v: %v\n", taskWorker, err)
+		w.WriteHeader(502)
+		return
+	}
+
+	workerR := bufio.NewReader(workerConn)
+	resp, err := http.ReadResponse(workerR, req)
+	if err != nil {
+		workerConn.Close()
+		log.Printf("Error reading exec response from %v: %v\n", taskWorker, err)
+		w.WriteHeader(502)
+		return
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer workerConn.Close()
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+		return
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		workerConn.Close()
+		w.WriteHeader(500)
+		return
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		workerConn.Close()
+		log.Printf("Error hijacking connection for exec in task %v: %v\n", tID, err)
+		return
+	}
+
+	execID := resp.Header.Get("X-Exec-Id")
+	fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: tcp\r\nX-Exec-Id: %s\r\n\r\n", execID)
+	utils.ProxyStream(conn, buf.Reader, workerConn, workerR)
+}
+
+func (a *Api) InspectExecHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	execID := chi.URLParam(r, "execID")
+	tID, _ := uuid.Parse(taskID)
+	taskWorker, ok := a.Manager.lastTaskWorker(tID)
+	if !ok {
+		msg := fmt.Sprintf("No worker found for task %v", taskID)
+		log.Println(msg)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: msg})
+		return
+	}
+
+	url := fmt.Sprintf("http://%s/tasks/%s/exec/%s", taskWorker, tID, execID)
+	resp, err := http.Get(url)
+	if err != nil {
+		msg := fmt.Sprintf("Error connecting to %v: %v", taskWorker, err)
+		log.Println(msg)
+		w.WriteHeader(502)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 502, Message: msg})
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
 }
@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (m *Manager) GetJobs() []*task.Job {
+	jobs, err := m.JobDb.List()
+	if err != nil {
+		log.Printf("error getting list of jobs: %v\n", err)
+		return nil
+	}
+	return jobs.([]*task.Job)
+}
+
+func (m *Manager) GetJob(name string) (*task.Job, error) {
+	result, err := m.JobDb.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*task.Job), nil
+}
+
+// AddJob stores a new job. Jobs cannot be changed once created, a job with
+// the same name has to be deleted first.
+func (m *Manager) AddJob(j task.Job) error {
+	m.jobMu.Lock()
+	defer m.jobMu.Unlock()
+	if _, err := m.GetJob(j.Name); err == nil {
+		return fmt.Errorf("job %s already exists", j.Name)
+	}
+	if j.Completions == 0 {
+		j.Completions = 1
+	}
+	if j.Parallelism == 0 {
+		j.Parallelism = 1
+	}
+	j.State = task.JobRunning
+	j.CreatedAt = time.Now().UTC()
+	return m.JobDb.Put(j.Name, &j)
+}
+
+// DeleteJob stops the running tasks of a job and removes it.
+func (m *Manager) DeleteJob(name string) error {
+	m.jobMu.Lock()
+	defer m.jobMu.Unlock()
+	j, err := m.GetJob(name)
+	if err != nil {
+		return err
+	}
+	m.stopJobTasks(j)
+	return m.JobDb.Delete(name)
+}
+
+func (m *Manager) ReconcileJobs() {
+	for {
+		log.Println("Reconciling jobs")
+		m.reconcileJobs()
+		log.Println("Job reconciliation completed")
+		log.Printf("Sleeping for %v\n", m.ReconcileInterval)
+		time.Sleep(m.ReconcileInterval)
+	}
+}
+
+func (m *Manager) reconcileJobs() {
+	m.jobMu.Lock()
+	defer m.jobMu.Unlock()
+	for _, j := range m.GetJobs() {
+		if !j.Finished() {
+			m.reconcileJob(j)
+		}
+	}
+}
+
+// reconcileJob counts the tasks of a job by outcome, decides whether the
+// job has succeeded or failed, and otherwise starts tasks up to its
+// parallelism. Tasks stopped on request count neither as success nor as
+// failure, and every restart of a task counts as a failure.
+func (m *Manager) reconcileJob(j *task.Job) {
+	owner := task.Owner{Kind: task.OwnerJob, Name: j.Name}
+	active, succeeded, failed := 0, 0, 0
+	for _, t := range m.ownedTasks(owner) {
+		failed += t.RestartCount
+		switch {
+		case t.Active():
+			active++
+		case t.State == task.Completed && t.Reason == task.ReasonCompleted:
+			succeeded++
+		case t.State == task.Failed:
+			failed++
+		}
+	}
+	j.Active, j.Succeeded, j.Failed = active, succeeded, failed
+	if j.StartTime.IsZero() {
+		j.StartTime = time.Now().UTC()
+	}
+
+	switch {
+	case succeeded >= j.Completions:
+		j.State = task.JobSucceeded
+	case failed > j.BackoffLimit:
+		j.State = task.JobFailed
+		j.Reason = task.ReasonBackoffLimitExceeded
+	case j.ActiveDeadline > 0 && time.Since(j.StartTime) > time.Duration(j.ActiveDeadline)*time.Second:
+		j.State = task.JobFailed
+		j.Reason = task.ReasonDeadlineExceeded
+	}
+
+	if j.Finished() {
+		log.Printf("Job %s %s with %d succeeded and %d failed tasks %s\n", j.Name, j.State, succeeded, failed, j.Reason)
+		j.CompletionTime = time.Now().UTC()
+		m.stopJobTasks(j)
+		j.Active = 0
+		m.JobDb.Put(j.Name, j)
+		return
+	}
+
+	want := min(j.Parallelism, j.Completions-succeeded) - active
+	if want > 0 {
+		log.Printf("Job %s has %d active and %d succeeded of %d tasks, creating %d\n", j.Name, active, succeeded, j.Completions, want)
+	}
+	for i := 0; i < want; i++ {
+		m.createOwnedTask(owner, j.Template)
+		j.Active++
+	}
+	m.JobDb.Put(j.Name, j)
+}
+
+func (m *Manager) stopJobTasks(j *task.Job) {
+	for _, t := range m.ownedTasks(task.Owner{Kind: task.OwnerJob, Name: j.Name}) {
+		if t.Active() {
+			m.requestStop(t)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (a *Api) StartJobHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	j := task.Job{}
+	err := d.Decode(&j)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = task.ValidateJob(j)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid job %s: %v", j.Name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.AddJob(j)
+	if err != nil {
+		msg := fmt.Sprintf("Error adding job %s: %v", j.Name, err)
+		log.Println(msg)
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 409, Message: msg})
+		return
+	}
+
+	stored, err := a.Manager.GetJob(j.Name)
+	if err != nil {
+		stored = &j
+	}
+	log.Printf("Added job %s with %d completions\n", stored.Name, stored.Completions)
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(stored)
+}
+
+func (a *Api) GetJobsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetJobs())
+}
+
+func (a *Api) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	j, err := a.Manager.GetJob(name)
+	if err != nil {
+		log.Printf("No job with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(j)
+}
+
+func (a *Api) DeleteJobHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.DeleteJob(name)
+	if err != nil {
+		log.Printf("No job with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	log.Printf("Deleted job %s\n", name)
+	w.WriteHeader(204)
+}
@@ -3,15 +3,13 @@ package manager
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
+	"slices"
+	"sync"
 	"time"
 
-	"github.com/docker/go-connections/nat"
-	"github.com/golang-collections/collections/queue"
 	"github.com/google/uuid"
 	"github.com/utsab818/my-orchestrator/node"
 	"github.com/utsab818/my-orchestrator/scheduler"
@@ -21,15 +19,50 @@ import (
 )
 
 type Manager struct {
-	Pending       queue.Queue
+	Pending       *TaskQueue
 	TaskDb        store.Store
 	EventDb       store.Store
+	ServiceDb     store.Store
+	RevisionDb    store.Store
+	JobDb         store.Store
+	CronDb        store.Store
+	WorkflowDb    store.Store
+	GroupDb       store.Store
 	Workers       []string // The format could be <hostname>:<port> as we pass host and port for worker to know which worker it is.
 	WorkerTaskMap map[string][]uuid.UUID
 	TaskWorkerMap map[uuid.UUID]string
 	LastWorker    int
 	WorkerNodes   []*node.Node
 	Scheduler     scheduler.Scheduler
+	NodeLostAfter time.Duration // how long a node may be unreachable before its tasks are moved
+
+	ProcessInterval   time.Duration // how often queued task events are sent to workers
+	UpdateInterval    time.Duration // how often workers are polled for task updates
+	RestartInterval   time.Duration // how often stopped tasks are checked for a restart
+	ReconcileInterval time.Duration // how often services, jobs, workflows and task groups are reconciled
+
+	// serviceMu serializes changes to services with their reconciliation.
+	serviceMu  sync.Mutex
+	jobMu      sync.Mutex
+	cronMu     sync.Mutex
+	workflowMu sync.Mutex
+	groupMu    sync.Mutex
+
+	// nodeMu guards Workers, WorkerNodes, WorkerTaskMap, TaskWorkerMap,
+	// preempting and groupReservations, which the API handlers and the
+	// manager's loops all change.
+	nodeMu sync.Mutex
+	// preempting holds the tasks being stopped to make room for tasks of
+	// higher priority. They keep their resources until they have stopped.
+	preempting map[uuid.UUID]bool
+	// groupReservations holds, by group name, the resources allocated for
+	// task groups whose tasks have not all been scheduled yet.
+	groupReservations map[string]task.Task
+
+	// restartNodes holds the node each task waiting to be restarted last
+	// ran on.
+	restartMu    sync.Mutex
+	restartNodes map[uuid.UUID]string
 }
 
 func New(workers []string, schedulerType string, dbType string) *Manager {
@@ -37,6 +70,7 @@ func New(workers []string, schedulerType string, dbType string) *Manager {
 	taskWorkerMap := make(map[uuid.UUID]string)
 
 	var nodes []*node.Node
+	workers = slices.DeleteFunc(workers, func(w string) bool { return w == "" })
 	for worker := range workers {
 		workerTaskMap[workers[worker]] = []uuid.UUID{}
 
@@ -56,12 +90,21 @@ func New(workers []string, schedulerType string, dbType string) *Manager {
 	}
 
 	m := Manager{
-		Pending:       *queue.New(),
+		Pending:       NewTaskQueue(),
 		Workers:       workers,
 		WorkerTaskMap: workerTaskMap,
 		TaskWorkerMap: taskWorkerMap,
 		WorkerNodes:   nodes,
 		Scheduler:     s,
+		NodeLostAfter: DefaultNodeLostAfter,
+
+		ProcessInterval:   DefaultProcessInterval,
+		UpdateInterval:    DefaultUpdateInterval,
+		RestartInterval:   DefaultRestartInterval,
+		ReconcileInterval: DefaultReconcileInterval,
+		preempting:        make(map[uuid.UUID]bool),
+		groupReservations: make(map[string]task.Task),
+		restartNodes:      make(map[uuid.UUID]string),
 	}
 
 	var ts store.Store //task
@@ -90,9 +133,30 @@ func New(workers []string, schedulerType string, dbType string) *Manager {
 
 	m.TaskDb = ts
 	m.EventDb = es
+	m.ServiceDb = newResourceStore[task.Service](dbType, "services")
+	m.RevisionDb = newResourceStore[task.ServiceRevision](dbType, "revisions")
+	m.JobDb = newResourceStore[task.Job](dbType, "jobs")
+	m.CronDb = newResourceStore[task.CronTask](dbType, "crontasks")
+	m.WorkflowDb = newResourceStore[task.Workflow](dbType, "workflows")
+	m.GroupDb = newResourceStore[task.TaskGroup](dbType, "groups")
 	return &m
 }
 
+// newResourceStore creates the store for a manager resource other than
+// tasks and task events, using the same kind of datastore as the tasks.
+func newResourceStore[T any](dbType string, name string) store.Store {
+	switch dbType {
+	case "persistent":
+		s, err := store.NewBoltStore[T](fmt.Sprintf("%s.db", name), 0600, name)
+		if err != nil {
+			log.Fatalf("unable to create %s store: %v", name, err)
+		}
+		return s
+	default:
+		return store.NewInMemoryStore[T]()
+	}
+}
+
 func (m *Manager) AddTask(te task.TaskEvent) {
 	m.Pending.Enqueue(te)
 }
@@ -101,8 +165,8 @@ func (m *Manager) ProcessTasks() {
 	for {
 		log.Println("Processing any tasks in the queue")
 		m.SendWork()
-		log.Println("Sleeping for 10 seconds")
-		time.Sleep(10 * time.Second)
+		log.Printf("Sleeping for %v\n", m.ProcessInterval)
+		time.Sleep(m.ProcessInterval)
 	}
 }
 
@@ -111,18 +175,28 @@ func (m *Manager) UpdateTasks() {
 		log.Println("Checking for task updates from workers")
 		m.updateTasks()
 		log.Println("Task updates completed")
-		log.Println("Sleeping for 15 seconds")
-		time.Sleep(15 * time.Second)
+		log.Printf("Sleeping for %v\n", m.UpdateInterval)
+		time.Sleep(m.UpdateInterval)
 	}
 }
 
 func (m *Manager) SelectWorker(t task.Task) (*node.Node, error) {
-	candidates := m.Scheduler.SelectCandidateNodes(t, m.WorkerNodes)
-	if candidates == nil {
-		msg := fmt.Sprintf("No available candidates match resource request for task %v", t.ID)
-		err := errors.New(msg)
+	return m.selectWorker(t, m.nodes())
+}
+
+func (m *Manager) selectWorker(t task.Task, nodes []*node.Node) (*node.Node, error) {
+	nodes = schedulableNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no schedulable nodes for task %v", t.ID)
+	}
+	nodes, err := scheduler.Filter(t, nodes)
+	if err != nil {
 		return nil, err
 	}
+	candidates := m.Scheduler.SelectCandidateNodes(t, nodes)
+	if candidates == nil {
+		return nil, fmt.Errorf("no node matches the node selector or affinity of task %v", t.ID)
+	}
 
 	scores := m.Scheduler.Score(t, candidates)
 	if scores == nil {
@@ -133,20 +207,52 @@ func (m *Manager) SelectWorker(t task.Task) (*node.Node, error) {
 	return selectedNode, nil
 }
 
+// placeTask returns the node a task has been pinned to, the node a
+// restarted task goes back to, or lets the scheduler select one. Tasks
+// pinned to a cordoned node may still run there.
+func (m *Manager) placeTask(t task.Task) (*node.Node, error) {
+	if t.Node == "" {
+		if n, err := m.restartNode(t); n != nil || err != nil {
+			return n, err
+		}
+		return m.SelectWorker(t)
+	}
+	n := m.getNode(t.Node)
+	if n == nil {
+		return nil, fmt.Errorf("task %v is pinned to unknown node %s", t.ID, t.Node)
+	}
+	if n.State != node.Ready {
+		return nil, fmt.Errorf("task %v is pinned to node %s, which is %s", t.ID, t.Node, n.State)
+	}
+	if n.Scheduling == node.Draining {
+		return nil, fmt.Errorf("task %v is pinned to node %s, which is being drained", t.ID, t.Node)
+	}
+	// A task of a group may use the room reserved for its group.
+	if r, ok := m.groupReservation(t.Owner); ok {
+		releaseFrom(n, &r)
+	}
+	if err := scheduler.Fits(t, n); err != nil {
+		return nil, fmt.Errorf("task %v is pinned to node %s: %v", t.ID, t.Node, err)
+	}
+	return n, nil
+}
+
 // For each worker
 // 1. Query the worker to get a list of its tasks.
 // 2. For each task, update its state in the manager’s database so it
 // matches the state from the worker.
 
 func (m *Manager) updateTasks() {
-	for _, worker := range m.Workers {
+	for _, n := range m.nodes() {
+		worker := n.Name
 		log.Printf("Checking worker %v for task updates", worker)
 		url := fmt.Sprintf("http://%s/tasks", worker)
 
 		// resp may be nil so resp.StatusCode and resp.Body might cause panic
-		resp, err := http.Get(url)
+		resp, err := workerClient.Get(url)
 		if err != nil {
 			log.Printf("Error connecting to %v: %v\n", worker, err)
+			m.updateNode(worker, (*node.Node).ContactFailed)
 			continue
 		}
 
@@ -154,8 +260,19 @@ func (m *Manager) updateTasks() {
 
 		if resp.StatusCode != http.StatusOK {
 			log.Printf("Unexpected response from worker %v: %v\n", worker, resp.Status)
+			m.updateNode(worker, (*node.Node).ContactFailed)
 			continue
 		}
+		if n.State == node.Lost {
+			log.Printf("Lost node %s can be reached again\n", worker)
+		}
+		m.updateNode(worker, (*node.Node).Contacted)
+		// Nodes listed with --workers report their capacity only when asked.
+		if n.Memory == 0 {
+			if s, err := n.GetStats(); err == nil {
+				m.Heartbeat(worker, *s)
+			}
+		}
 
 		d := json.NewDecoder(resp.Body)
 		var tasks []*task.Task
@@ -178,7 +295,21 @@ func (m *Manager) updateTasks() {
 				continue
 			}
 
+			// Reports about the run before the task was restarted are stale.
+			// A node that was lost may still be running a task that has
+			// since been moved elsewhere, so it is stopped there.
+			if t.StartTime.Before(taskPersisted.NextRestart) {
+				if w, _ := m.taskWorker(t.ID); t.State == task.Running && w != worker {
+					log.Printf("Stopping task %s on %s, it has been moved\n", t.ID, worker)
+					m.stopTask(worker, t.ID.String())
+				}
+				continue
+			}
+
 			if taskPersisted.State != t.State {
+				if t.State == task.Completed || t.State == task.Failed {
+					m.release(worker, taskPersisted)
+				}
 				taskPersisted.State = t.State
 			}
 
@@ -186,10 +317,17 @@ func (m *Manager) updateTasks() {
 			taskPersisted.FinishTime = t.FinishTime
 			taskPersisted.ContainerId = t.ContainerId
 			taskPersisted.HostPorts = t.HostPorts
+			taskPersisted.Ready = t.Ready
+			taskPersisted.ExitCode = t.ExitCode
+			taskPersisted.OOMKilled = t.OOMKilled
+			taskPersisted.Error = t.Error
+			taskPersisted.Reason = t.Reason
+			taskPersisted.StopResult = t.StopResult
 
 			m.TaskDb.Put(taskPersisted.ID.String(), taskPersisted)
 		}
 	}
+	m.checkNodes()
 }
 
 // 1. Checks whether there are task events in the Pending queue
@@ -202,9 +340,7 @@ func (m *Manager) updateTasks() {
 // 8. Checks the response from the worker
 
 func (m *Manager) SendWork() {
-	if m.Pending.Len() > 0 {
-		e := m.Pending.Dequeue()
-		te := e.(task.TaskEvent) // convert event to taskEvent type as items gets stored as the interface{} type.
+	if te, ok := m.Pending.Dequeue(); ok {
 		err := m.EventDb.Put(te.ID.String(), &te)
 		if err != nil {
 			log.Printf("error attempting to store task event %s: %s\n", te.ID.String(), err)
@@ -212,7 +348,7 @@ func (m *Manager) SendWork() {
 		}
 		log.Printf("Pulled %v off pending queue\n", te)
 
-		taskWorker, ok := m.TaskWorkerMap[te.Task.ID]
+		taskWorker, ok := m.taskWorker(te.Task.ID)
 		if ok {
 			result, err := m.TaskDb.Get(te.Task.ID.String())
 			if err != nil {
@@ -236,16 +372,43 @@ func (m *Manager) SendWork() {
 			return
 		}
 
+		// The task has not been sent to a worker yet, so a stop only needs
+		// to be recorded and a start requested before it is dropped.
+		if te.State == task.Completed {
+			result, err := m.TaskDb.Get(te.Task.ID.String())
+			if err == nil {
+				persistedTask := result.(*task.Task)
+				persistedTask.State = task.Completed
+				persistedTask.Reason = task.ReasonStopped
+				persistedTask.FinishTime = time.Now().UTC()
+				m.TaskDb.Put(persistedTask.ID.String(), persistedTask)
+			}
+			log.Printf("task %s was stopped before being scheduled\n", te.Task.ID)
+			return
+		}
+		if result, err := m.TaskDb.Get(te.Task.ID.String()); err == nil && result.(*task.Task).StopRequested {
+			log.Printf("task %s was stopped before being scheduled\n", te.Task.ID)
+			return
+		}
+
 		t := te.Task
-		w, err := m.SelectWorker(t)
+		w, err := m.placeTask(t)
 		if err != nil {
 			log.Printf("error selecting worker for task %s: %v\n", t.ID, err)
+			if m.preempt(t) {
+				m.Pending.EnqueueAfter(te, preemptRetryDelay)
+				return
+			}
+			m.markUnschedulable(t, err)
+			m.Pending.EnqueueAfter(te, requeueDelay)
+			return
 		}
-		m.WorkerTaskMap[w.Name] = append(m.WorkerTaskMap[w.Name], te.Task.ID)
-		m.TaskWorkerMap[t.ID] = w.Name
+		m.assignTask(w.Name, t.ID)
+		m.allocate(w.Name, &t)
 
 		t.State = task.Scheduled
 		m.TaskDb.Put(t.ID.String(), &t)
+		te.Task.State = task.Scheduled
 
 		data, err := json.Marshal(te)
 		if err != nil {
@@ -256,18 +419,24 @@ func (m *Manager) SendWork() {
 		resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
 		if err != nil {
 			log.Printf("Error connecting to %v: %v\n", w.Name, err)
-			m.Pending.Enqueue(te)
+			m.requeueTask(w.Name, te)
 			return
 		}
+		defer resp.Body.Close()
 		d := json.NewDecoder(resp.Body)
 		if resp.StatusCode != http.StatusCreated {
 			e := worker.ErrResponse{}
 			err := d.Decode(&e)
 			if err != nil {
 				fmt.Printf("Error decoding response: %s\n", err.Error())
-				return
+				e = worker.ErrResponse{HTTPStatusCode: resp.StatusCode, Message: resp.Status}
 			}
 			log.Printf("Response error (%d): %s", e.HTTPStatusCode, e.Message)
+			if resp.StatusCode >= http.StatusInternalServerError {
+				m.requeueTask(w.Name, te)
+				return
+			}
+			m.rejectTask(w.Name, te.Task, e.Message)
 			return
 		}
 
@@ -283,151 +452,120 @@ func (m *Manager) SendWork() {
 	}
 }
 
-func (m *Manager) stopTask(worker string, taskID string) {
-	client := &http.Client{}
-	url := fmt.Sprintf("http://%s/tasks/%s", worker, taskID)
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		log.Printf("error creating request to delete task %s: %v\n", taskID, err)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("error connecting to worker at %s: %v\n", url, err)
-		return
-	}
-
-	if resp.StatusCode != 204 {
-		log.Printf("Error sending request: %v\n", err)
-		return
+func (m *Manager) getNode(name string) *node.Node {
+	for _, n := range m.nodes() {
+		if n.Name == name {
+			return n
+		}
 	}
+	return nil
+}
 
-	log.Printf("task %s has been scheduled to be stopped", taskID)
+// requeueTask takes back a task a worker could not be reached for or
+// failed to start, and queues it to be scheduled again after requeueDelay.
+func (m *Manager) requeueTask(worker string, te task.TaskEvent) {
+	t := te.Task
+	m.unassignTask(worker, t.ID)
+	m.release(worker, &t)
+	t.State = task.Pending
+	m.TaskDb.Put(t.ID.String(), &t)
+	te.Task.State = task.Pending
+	m.Pending.EnqueueAfter(te, requeueDelay)
 }
 
-func (m *Manager) GetTasks() []*task.Task {
-	taskList, err := m.TaskDb.List()
-	if err != nil {
-		log.Printf("error getting list of tasks: %v\n", err)
-		return nil
-	}
-	return taskList.([]*task.Task) // convert any to *task.Task and return
+// rejectTask takes back a task a worker refused to run and fails it with
+// the worker's message.
+func (m *Manager) rejectTask(worker string, t task.Task, message string) {
+	m.unassignTask(worker, t.ID)
+	m.release(worker, &t)
+	t.State = task.Failed
+	t.Reason = task.ReasonFailed
+	t.Error = message
+	t.FinishTime = time.Now().UTC()
+	m.TaskDb.Put(t.ID.String(), &t)
 }
 
-func (m *Manager) checkTaskHealth(t task.Task) error {
-	log.Printf("Calling health check for task %s: %s\n", t.ID, t.HealthCheck)
-	w, ok := m.TaskWorkerMap[t.ID]
-	if !ok || w == "" {
-		log.Printf("Error: Task ID %s not found in TaskWorkerMap", t.ID)
-		return fmt.Errorf("task ID %s not found in TaskWorkerMap", t.ID)
-	}
-	hostPort := getHostPort(t.HostPorts)
-	if hostPort == nil {
-		log.Printf("Error: getHostPort returned nil for task %s", t.ID)
-		return fmt.Errorf("invalid host port for task %s", t.ID)
+// markUnschedulable records on a pending task why it could not be placed.
+func (m *Manager) markUnschedulable(t task.Task, err error) {
+	if result, dbErr := m.TaskDb.Get(t.ID.String()); dbErr == nil {
+		t = *result.(*task.Task)
 	}
-	worker := strings.Split(w, ":")
-	url := fmt.Sprintf("http://%s:%s%s", worker[0], *hostPort, t.HealthCheck)
-	log.Printf("Calling health check for task %s: %s\n", t.ID, url)
-	resp, err := http.Get(url)
-	if err != nil {
-		msg := fmt.Sprintf("Error connecting to health check %s", url)
-		log.Println(msg)
-		return errors.New(msg)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("Error health check for task %s did not return 200\n", t.ID)
-		log.Println(msg)
-		return errors.New(msg)
+	if !t.Active() {
+		return
 	}
-
-	log.Printf("Task %s health check response: %v\n", t.ID, resp.StatusCode)
-
-	return nil
+	t.State = task.Pending
+	t.Reason = task.ReasonUnschedulable
+	t.Error = err.Error()
+	m.TaskDb.Put(t.ID.String(), &t)
 }
 
-func getHostPort(ports nat.PortMap) *string {
-	for k := range ports {
-		return &ports[k][0].HostPort
-	}
-	return nil
+// allocate reserves the resources and host ports of a task on the node it
+// was placed on.
+func (m *Manager) allocate(worker string, t *task.Task) {
+	m.updateNode(worker, func(n *node.Node) { allocateTo(n, t) })
 }
 
-func (m *Manager) doHealthChecks() {
-	for _, t := range m.GetTasks() {
-		if t == nil {
-			log.Println("Warning: Encountered nil task in doHealthChecks")
-			continue
-		}
-		if t.State == task.Running && t.RestartCount < 3 {
-			err := m.checkTaskHealth(*t)
-			if err != nil {
-				if t.RestartCount < 3 {
-					m.restartTask(t)
-				}
-			} else if t.State == task.Failed && t.RestartCount < 3 {
-				m.restartTask(t)
-			}
+func allocateTo(n *node.Node, t *task.Task) {
+	n.CpuAllocated += t.Cpu
+	n.MemoryAllocated += t.Memory / 1024
+	n.DiskAllocated += t.DiskRequest()
+	for _, p := range t.BoundHostPorts() {
+		if n.HostPortsAllocated == nil {
+			n.HostPortsAllocated = make(map[string]bool)
 		}
+		n.HostPortsAllocated[p] = true
+	}
+	if n.TaskLabels == nil {
+		n.TaskLabels = make(map[string]map[string]string)
 	}
+	n.TaskLabels[t.ID.String()] = t.Labels
+	n.TaskCount++
 }
 
-func (m *Manager) restartTask(t *task.Task) {
-	w := m.TaskWorkerMap[t.ID]
-	t.State = task.Scheduled
-	t.RestartCount++
-	m.TaskDb.Put(t.ID.String(), t)
-
-	te := task.TaskEvent{
-		ID:        uuid.New(),
-		State:     task.Running,
-		Timestamp: time.Now(),
-		Task:      *t,
+// release returns the resources and host ports reserved for a finished
+// task, including its volume size requests, to the node it ran on.
+func (m *Manager) release(worker string, t *task.Task) {
+	m.updateNode(worker, func(n *node.Node) { releaseFrom(n, t) })
+}
+
+func releaseFrom(n *node.Node, t *task.Task) {
+	n.CpuAllocated = max(n.CpuAllocated-t.Cpu, 0)
+	n.MemoryAllocated = max(n.MemoryAllocated-t.Memory/1024, 0)
+	n.DiskAllocated = max(n.DiskAllocated-t.DiskRequest(), 0)
+	for _, p := range t.BoundHostPorts() {
+		delete(n.HostPortsAllocated, p)
 	}
+	delete(n.TaskLabels, t.ID.String())
+	n.TaskCount = max(n.TaskCount-1, 0)
+}
 
-	data, err := json.Marshal(te)
+func (m *Manager) stopTask(worker string, taskID string) {
+	client := &http.Client{}
+	url := fmt.Sprintf("http://%s/tasks/%s", worker, taskID)
+	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
-		log.Printf("Unable to marshal task object: %v.", t)
-		return
+		log.Printf("error creating request to delete task %s: %v\n", taskID, err)
 	}
 
-	url := fmt.Sprintf("http://%s/tasks", w)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error connecting to %v: %v", w, err)
-		m.Pending.Enqueue(t)
+		log.Printf("error connecting to worker at %s: %v\n", url, err)
 		return
 	}
 
-	d := json.NewDecoder(resp.Body)
-	if resp.StatusCode != http.StatusCreated {
-		e := worker.ErrResponse{}
-		err := d.Decode(&e)
-		if err != nil {
-			fmt.Printf("Error decoding response: %s\n", err.Error())
-			return
-		}
-
-		log.Printf("Response error (%d): %s", e.HTTPStatusCode, e.Message)
+	if resp.StatusCode != 204 {
+		log.Printf("Error sending request: %v\n", err)
 		return
 	}
 
-	newTask := task.Task{}
-	err = d.Decode(&newTask)
-	if err != nil {
-		fmt.Printf("Error decoding response: %s\n", err.Error())
-		return
-	}
-	log.Printf("%#v\n", t)
+	log.Printf("task %s has been scheduled to be stopped", taskID)
 }
 
-func (m *Manager) DoHealthChecks() {
-	for {
-		log.Println("Performing task health check")
-		m.doHealthChecks()
-		log.Println("Task health checks completed")
-		log.Println("Sleeping for 60 seconds")
-		time.Sleep(60 * time.Second)
+func (m *Manager) GetTasks() []*task.Task {
+	taskList, err := m.TaskDb.List()
+	if err != nil {
+		log.Printf("error getting list of tasks: %v\n", err)
+		return nil
 	}
+	return taskList.([]*task.Task) // convert any to *task.Task and return
 }
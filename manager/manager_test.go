@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/task"
+	"github.com/utsab818/my-orchestrator/worker"
+)
+
+// rejectingWorker returns the address of a worker that answers every
+// request with status and an ErrResponse, or a body that is not one.
+func rejectingWorker(t *testing.T, status int, errResponse bool) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if !errResponse {
+			w.Write([]byte("bad gateway"))
+			return
+		}
+		json.NewEncoder(w).Encode(worker.ErrResponse{HTTPStatusCode: status, Message: "rejected"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+func TestSendWorkRejectedTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		errResponse bool
+		state       task.State
+		queued      int
+	}{
+		{"refused by the worker", http.StatusBadRequest, true, task.Failed, 0},
+		{"worker failed", http.StatusInternalServerError, true, task.Pending, 1},
+		{"undecodable error", http.StatusBadGateway, false, task.Pending, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := rejectingWorker(t, tt.status, tt.errResponse)
+			m := newPreemptManager(t, addr)
+			tk := task.Task{ID: uuid.New(), Name: "web", State: task.Pending, Image: "web", Cpu: 1,
+				PortBindings: map[string]string{"80/tcp": "8080"}}
+			m.TaskDb.Put(tk.ID.String(), &tk)
+			m.AddTask(task.TaskEvent{ID: uuid.New(), State: task.Running, Task: tk})
+
+			m.SendWork()
+
+			n := m.getNode(addr)
+			if n.CpuAllocated != 0 || n.TaskCount != 0 || len(n.HostPortsAllocated) != 0 {
+				t.Errorf("node still holds %v cores, %d tasks and ports %v", n.CpuAllocated, n.TaskCount, n.HostPortsAllocated)
+			}
+			if w, ok := m.taskWorker(tk.ID); ok {
+				t.Errorf("task is still assigned to %s", w)
+			}
+			got, _ := m.TaskDb.Get(tk.ID.String())
+			if s := got.(*task.Task).State; s != tt.state {
+				t.Errorf("task is %v, want %v", s, tt.state)
+			}
+			if tt.state == task.Failed && got.(*task.Task).Error != "rejected" {
+				t.Errorf("error = %q, want the worker's message", got.(*task.Task).Error)
+			}
+			if m.Pending.Len() != tt.queued {
+				t.Errorf("%d events queued, want %d", m.Pending.Len(), tt.queued)
+			}
+		})
+	}
+}
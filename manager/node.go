@@ -0,0 +1,300 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/stats"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// DefaultNodeLostAfter is how long a node may be unreachable before it is
+// considered lost.
+const DefaultNodeLostAfter = 60 * time.Second
+
+// Default intervals of the loops that send task events to workers, poll
+// workers for task updates, restart tasks and reconcile services, jobs,
+// workflows and task groups.
+const (
+	DefaultProcessInterval   = 10 * time.Second
+	DefaultUpdateInterval    = 15 * time.Second
+	DefaultRestartInterval   = 5 * time.Second
+	DefaultReconcileInterval = 10 * time.Second
+)
+
+// workerClient is used to poll workers, so an unresponsive node fails the
+// contact instead of stalling the manager.
+var workerClient = &http.Client{Timeout: 5 * time.Second}
+
+// checkNodes marks nodes that have not been reached within NodeLostAfter
+// as lost and moves their tasks to other nodes.
+func (m *Manager) checkNodes() {
+	for _, n := range m.nodes() {
+		if n.State == node.Lost || time.Since(n.LastContact) < m.NodeLostAfter {
+			continue
+		}
+		log.Printf("Node %s has not been reached since %v, marking it as lost\n", n.Name, n.LastContact)
+		m.updateNode(n.Name, func(n *node.Node) { n.State = node.Lost })
+		m.evacuateNode(n)
+	}
+}
+
+// evacuateNode reschedules the tasks of a lost node. Tasks pinned to the
+// node cannot move and fail instead, as do tasks that were being stopped.
+func (m *Manager) evacuateNode(n *node.Node) {
+	now := time.Now().UTC()
+	for _, t := range m.GetTasks() {
+		if w, _ := m.taskWorker(t.ID); w != n.Name || (t.State != task.Scheduled && t.State != task.Running) {
+			continue
+		}
+		t.Error = fmt.Sprintf("node %s was lost", n.Name)
+		if t.StopRequested || t.Node != "" {
+			m.unassignTask(n.Name, t.ID)
+			m.release(n.Name, t)
+			t.State = task.Failed
+			t.Reason = task.ReasonNodeLost
+			t.Ready = false
+			t.FinishTime = now
+			// Anything the node reports about the task once it is back
+			// is stale.
+			t.NextRestart = now
+			m.TaskDb.Put(t.ID.String(), t)
+			log.Printf("Task %s failed as node %s was lost\n", t.ID, n.Name)
+			continue
+		}
+
+		t.RecordRestart(now)
+		m.resubmitTask(n.Name, t, task.ReasonNodeLost)
+		log.Printf("Rescheduling task %s from lost node %s\n", t.ID, n.Name)
+	}
+}
+
+// resubmitTask takes a task off the worker it was sent to and queues it to
+// be scheduled again. Anything the worker reports about the task from then
+// on is stale. A preempted task is held back as long as the task that
+// preempted it, so that one is ready first and takes the room made for it.
+func (m *Manager) resubmitTask(worker string, t *task.Task, reason string) {
+	now := time.Now().UTC()
+	m.unassignTask(worker, t.ID)
+	m.release(worker, t)
+
+	t.State = task.Pending
+	t.Reason = reason
+	t.Ready = false
+	t.NextRestart = now
+	m.TaskDb.Put(t.ID.String(), t)
+	te := task.TaskEvent{
+		ID:        uuid.New(),
+		State:     task.Running,
+		Timestamp: now,
+		Task:      *t,
+	}
+	if reason == task.ReasonPreempted {
+		m.Pending.EnqueueAfter(te, preemptRetryDelay)
+		return
+	}
+	m.AddTask(te)
+}
+
+// nodes returns a snapshot of the worker nodes. The nodes are copies, so
+// they can be read while the manager's loops change the nodes through
+// updateNode.
+func (m *Manager) nodes() []*node.Node {
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	nodes := make([]*node.Node, len(m.WorkerNodes))
+	for i, n := range m.WorkerNodes {
+		nodes[i] = copyNode(n)
+	}
+	return nodes
+}
+
+// updateNode applies change to a node while holding nodeMu and returns a
+// copy of the changed node, or nil if the node is not registered.
+func (m *Manager) updateNode(name string, change func(n *node.Node)) *node.Node {
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	for _, n := range m.WorkerNodes {
+		if n.Name == name {
+			change(n)
+			return copyNode(n)
+		}
+	}
+	return nil
+}
+
+func copyNode(n *node.Node) *node.Node {
+	c := *n
+	c.HostPortsAllocated = maps.Clone(n.HostPortsAllocated)
+	c.TaskLabels = maps.Clone(n.TaskLabels)
+	c.Labels = maps.Clone(n.Labels)
+	c.Taints = slices.Clone(n.Taints)
+	return &c
+}
+
+// RegisterNode adds a worker that registered itself to the nodes tasks are
+// scheduled on, or updates the node of a worker registering again. It
+// reports whether the node is new. As with TaintNode, tasks that do not
+// tolerate a NoExecute taint the node registers with anew are moved to
+// other nodes in the background.
+func (m *Manager) RegisterNode(r node.Registration) (*node.Node, bool) {
+	m.nodeMu.Lock()
+	var n *node.Node
+	for _, wn := range m.WorkerNodes {
+		if wn.Name == r.Address {
+			n = wn
+		}
+	}
+	created := n == nil
+	if created {
+		n = node.NewNode(r.Address, fmt.Sprintf("http://%s", r.Address), "worker")
+		m.WorkerNodes = append(m.WorkerNodes, n)
+		m.Workers = append(m.Workers, r.Address)
+		m.WorkerTaskMap[r.Address] = []uuid.UUID{}
+	}
+	n.WorkerName = r.Name
+	n.Cores = r.Cores
+	n.Memory = r.Memory
+	n.Disk = r.Disk
+	n.Labels = r.Labels
+	evict := false
+	for _, t := range r.Taints {
+		if t.Effect == node.NoExecute && !slices.Contains(n.Taints, t) {
+			evict = true
+		}
+	}
+	n.Taints = r.Taints
+	n.Contacted()
+	c := copyNode(n)
+	m.nodeMu.Unlock()
+
+	if evict && !created {
+		go m.evictUntolerated(c)
+	}
+	return c, created
+}
+
+// Heartbeat records that a worker is alive along with its latest stats.
+func (m *Manager) Heartbeat(name string, s stats.Stats) error {
+	n := m.updateNode(name, func(n *node.Node) {
+		if s.MemStats != nil {
+			n.Memory = int(s.MemTotalKb())
+		}
+		if s.DiskStats != nil {
+			n.Disk = int(s.DiskTotal())
+		}
+		if s.Cores > 0 {
+			n.Cores = s.Cores
+		}
+		n.Stats = s
+		n.Contacted()
+	})
+	if n == nil {
+		return fmt.Errorf("node %s is not registered", name)
+	}
+	return nil
+}
+
+// LabelNode sets the labels of a node that have a value in changes and
+// removes those that are nil. A worker registering again replaces the
+// labels with the ones it was started with.
+func (m *Manager) LabelNode(name string, changes map[string]*string) (*node.Node, error) {
+	n := m.updateNode(name, func(n *node.Node) {
+		labels := maps.Clone(n.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(labels, k)
+			} else {
+				labels[k] = *v
+			}
+		}
+		n.Labels = labels
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	log.Printf("Set labels of node %s to %v\n", name, n.Labels)
+	return n, nil
+}
+
+// RemoveNode stops scheduling tasks on a node, moves its tasks to other
+// nodes and forgets about it.
+func (m *Manager) RemoveNode(name string) error {
+	n := m.updateNode(name, func(n *node.Node) { n.State = node.Lost })
+	if n == nil {
+		return fmt.Errorf("node %s is not registered", name)
+	}
+	m.evacuateNode(n)
+
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	m.WorkerNodes = slices.DeleteFunc(m.WorkerNodes, func(wn *node.Node) bool { return wn.Name == name })
+	m.Workers = slices.DeleteFunc(m.Workers, func(w string) bool { return w == name })
+	delete(m.WorkerTaskMap, name)
+	log.Printf("Removed node %s\n", name)
+	return nil
+}
+
+// taskWorker returns the worker a task was sent to.
+func (m *Manager) taskWorker(id uuid.UUID) (string, bool) {
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	w, ok := m.TaskWorkerMap[id]
+	return w, ok
+}
+
+// lastTaskWorker returns the worker a task was sent to or, while it waits
+// to be restarted, the worker it last ran on, which still has its logs.
+func (m *Manager) lastTaskWorker(id uuid.UUID) (string, bool) {
+	if w, ok := m.taskWorker(id); ok {
+		return w, true
+	}
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+	w, ok := m.restartNodes[id]
+	return w, ok
+}
+
+// assignTask records that a task was sent to a worker, which replaces the
+// worker a restarted task last ran on.
+func (m *Manager) assignTask(worker string, id uuid.UUID) {
+	m.restartMu.Lock()
+	delete(m.restartNodes, id)
+	m.restartMu.Unlock()
+
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	m.WorkerTaskMap[worker] = append(m.WorkerTaskMap[worker], id)
+	m.TaskWorkerMap[id] = worker
+}
+
+// unassignTask forgets that a task was sent to a worker.
+func (m *Manager) unassignTask(worker string, id uuid.UUID) {
+	m.nodeMu.Lock()
+	defer m.nodeMu.Unlock()
+	delete(m.TaskWorkerMap, id)
+	delete(m.preempting, id)
+	if ids, ok := m.WorkerTaskMap[worker]; ok {
+		m.WorkerTaskMap[worker] = removeTaskID(ids, id)
+	}
+}
+
+// schedulableNodes returns the nodes new tasks can be scheduled on.
+func schedulableNodes(nodes []*node.Node) []*node.Node {
+	var schedulable []*node.Node
+	for _, n := range nodes {
+		if n.AcceptsTasks() {
+			schedulable = append(schedulable, n)
+		}
+	}
+	return schedulable
+}
@@ -0,0 +1,214 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/stats"
+)
+
+func (a *Api) RegisterNodeHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	reg := node.Registration{}
+	err := d.Decode(&reg)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+	if reg.Address == "" {
+		msg := "Address is required"
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+	for _, t := range reg.Taints {
+		if err := node.ValidateTaint(t); err != nil {
+			log.Println(err)
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: err.Error()})
+			return
+		}
+	}
+
+	n, created := a.Manager.RegisterNode(reg)
+	status := 200
+	if created {
+		status = 201
+		log.Printf("Registered worker %s at %s\n", reg.Name, reg.Address)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(n)
+}
+
+func (a *Api) HeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	s := stats.Stats{}
+	err := json.NewDecoder(r.Body).Decode(&s)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.Heartbeat(name, s)
+	if err != nil {
+		log.Printf("Heartbeat from unknown node: %v\n", err)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *Api) RemoveNodeHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.RemoveNode(name)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+	w.WriteHeader(204)
+}
+
+// LabelNodeHandler sets the labels of a node given in the body and
+// removes those given as null.
+func (a *Api) LabelNodeHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	changes := map[string]*string{}
+	err := json.NewDecoder(r.Body).Decode(&changes)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+	if _, ok := changes[""]; ok {
+		msg := "label keys must not be empty"
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	n, err := a.Manager.LabelNode(name, changes)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(n)
+}
+
+func (a *Api) TaintNodeHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	taint := node.Taint{}
+	err := d.Decode(&taint)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+	if err := node.ValidateTaint(taint); err != nil {
+		log.Println(err)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: err.Error()})
+		return
+	}
+
+	n, err := a.Manager.TaintNode(name, taint)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(n)
+}
+
+// UntaintNodeHandler removes the taints with a key from a node. The
+// optional effect query parameter only removes the taint with that effect.
+func (a *Api) UntaintNodeHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	key := chi.URLParam(r, "key")
+	n, err := a.Manager.UntaintNode(name, key, r.URL.Query().Get("effect"))
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(n)
+}
+
+func (a *Api) CordonNodeHandler(w http.ResponseWriter, r *http.Request) {
+	a.nodeSchedulingResponse(w, a.Manager.CordonNode, chi.URLParam(r, "name"), 200)
+}
+
+func (a *Api) UncordonNodeHandler(w http.ResponseWriter, r *http.Request) {
+	a.nodeSchedulingResponse(w, a.Manager.UncordonNode, chi.URLParam(r, "name"), 200)
+}
+
+// DrainNodeHandler starts draining a node. The optional timeout query
+// parameter is a duration such as 90s.
+func (a *Api) DrainNodeHandler(w http.ResponseWriter, r *http.Request) {
+	timeout := DefaultDrainTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			msg := fmt.Sprintf("Invalid timeout %q", v)
+			log.Println(msg)
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+			return
+		}
+		timeout = d
+	}
+	drain := func(name string) (*node.Node, error) { return a.Manager.DrainNode(name, timeout) }
+	a.nodeSchedulingResponse(w, drain, chi.URLParam(r, "name"), 202)
+}
+
+func (a *Api) nodeSchedulingResponse(w http.ResponseWriter, change func(string) (*node.Node, error), name string, status int) {
+	n, err := change(name)
+	if err != nil {
+		log.Println(err)
+		code := 409
+		if a.Manager.getNode(name) == nil {
+			code = 404
+		}
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: code, Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(n)
+}
@@ -0,0 +1,133 @@
+package manager
+
+import (
+	"cmp"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/scheduler"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// requeueDelay is how long an event that could not be sent to a worker is
+// held back, so the events behind it are not starved.
+const requeueDelay = 30 * time.Second
+
+// preemptRetryDelay is how long the event of a task that preempted others
+// is held back, giving the worker time to stop them.
+const preemptRetryDelay = 5 * time.Second
+
+// preemptStopTimeout is how long a preempted task may take to stop before
+// its resources are released anyway.
+const preemptStopTimeout = 2 * time.Minute
+
+// preempt makes room for a task that fits on no node by stopping tasks of
+// lower priority on the node where the fewest and least important of them
+// have to go. The stopped tasks are queued to be scheduled again once the
+// worker has stopped them. Tasks pinned to a node are neither preempted nor
+// preempt others. It reports whether room is being made for the task.
+func (m *Manager) preempt(t task.Task) bool {
+	if t.Node != "" {
+		return false
+	}
+	tasks := m.GetTasks()
+	var target *node.Node
+	var victims []*task.Task
+	for _, n := range schedulableNodes(m.nodes()) {
+		v := m.preemptionVictims(t, n, tasks)
+		if v == nil {
+			continue
+		}
+		if victims == nil || fewerVictims(v, victims) {
+			target, victims = n, v
+		}
+	}
+	if target == nil {
+		return false
+	}
+	if len(victims) == 0 {
+		log.Printf("Waiting for preempted tasks on node %s to stop for task %s\n", target.Name, t.ID)
+		return true
+	}
+
+	stopping := make(map[uuid.UUID]*task.Task)
+	now := time.Now().UTC()
+	for _, v := range victims {
+		log.Printf("Preempting task %s with priority %d on node %s for task %s with priority %d\n",
+			v.ID, v.Priority, target.Name, t.ID, t.Priority)
+		m.nodeMu.Lock()
+		m.preempting[v.ID] = true
+		m.nodeMu.Unlock()
+		// Anything the worker reports about the task from here on is stale.
+		v.NextRestart = now
+		m.TaskDb.Put(v.ID.String(), v)
+		m.stopTask(target.Name, v.ID.String())
+		stopping[v.ID] = v
+	}
+	go m.moveWhenStopped(target.Name, stopping, preemptStopTimeout, task.ReasonPreempted)
+	return true
+}
+
+// preemptionVictims returns the tasks of lower priority on a node that have
+// to be stopped for t to fit on it, lowest priority and most recently
+// started first, or nil if stopping them would not make room. Tasks already
+// being preempted count as gone, so it returns no victims when they make
+// room.
+func (m *Manager) preemptionVictims(t task.Task, n *node.Node, tasks []*task.Task) []*task.Task {
+	sim := copyNode(n)
+	var candidates []*task.Task
+	for _, c := range tasks {
+		w, _ := m.taskWorker(c.ID)
+		if w != n.Name || !c.Active() || c.State == task.Pending {
+			continue
+		}
+		m.nodeMu.Lock()
+		preempting := m.preempting[c.ID]
+		m.nodeMu.Unlock()
+		if preempting {
+			releaseFrom(sim, c)
+			continue
+		}
+		if c.Node == "" && c.Priority < t.Priority {
+			candidates = append(candidates, c)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b *task.Task) int {
+		if c := cmp.Compare(a.Priority, b.Priority); c != 0 {
+			return c
+		}
+		return b.StartTime.Compare(a.StartTime)
+	})
+
+	if fitsAfterPreemption(t, sim) {
+		return []*task.Task{}
+	}
+	for i, c := range candidates {
+		releaseFrom(sim, c)
+		if fitsAfterPreemption(t, sim) {
+			return candidates[:i+1]
+		}
+	}
+	return nil
+}
+
+func fitsAfterPreemption(t task.Task, n *node.Node) bool {
+	return scheduler.Fits(t, n) == nil && scheduler.MatchesAffinity(t, n) && scheduler.ToleratesTaints(t, n)
+}
+
+// fewerVictims reports whether preempting a is better than preempting b: it
+// stops tasks of lower priority, or as low but fewer of them.
+func fewerVictims(a, b []*task.Task) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) < len(b)
+	}
+	// Victims are sorted by priority, so the last one has the highest.
+	pa, pb := a[len(a)-1].Priority, b[len(b)-1].Priority
+	if pa != pb {
+		return pa < pb
+	}
+	return len(a) < len(b)
+}
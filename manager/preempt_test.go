@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// newPreemptManager returns a manager with ready nodes of two cores each.
+func newPreemptManager(t *testing.T, nodes ...string) *Manager {
+	t.Helper()
+	m := New(nodes, "roundrobin", "memory")
+	for _, name := range nodes {
+		m.updateNode(name, func(n *node.Node) {
+			n.Cores = 2
+			n.Contacted()
+		})
+	}
+	return m
+}
+
+// runOn records a task as running on a node and reserves its resources.
+func runOn(m *Manager, worker string, name string, priority int, started time.Time) *task.Task {
+	t := &task.Task{
+		ID:        uuid.New(),
+		Name:      name,
+		State:     task.Running,
+		Cpu:       1,
+		Priority:  priority,
+		StartTime: started,
+	}
+	m.TaskDb.Put(t.ID.String(), t)
+	m.assignTask(worker, t.ID)
+	m.allocate(worker, t)
+	return t
+}
+
+func victimNames(victims []*task.Task) []string {
+	names := []string{}
+	for _, v := range victims {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestPreemptionVictims(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name     string
+		priority int
+		cpu      float64
+		want     []string // nil when preemption cannot make room
+	}{
+		{"lowest priority goes first", 10, 1, []string{"low"}},
+		{"victims are taken from the lowest priority up", 10, 2, []string{"low", "mid"}},
+		{"only lower priorities are preempted", 5, 1, []string{"low"}},
+		{"not enough room in lower priorities", 5, 2, nil},
+		{"tasks of equal priority are kept", 0, 1, nil},
+		{"not enough room even without the victims", 10, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newPreemptManager(t, "a")
+			runOn(m, "a", "mid", 5, now.Add(-time.Hour))
+			runOn(m, "a", "low", 0, now)
+
+			p := task.Task{ID: uuid.New(), Name: "p", Priority: tt.priority, Cpu: tt.cpu}
+			got := m.preemptionVictims(p, m.getNode("a"), m.GetTasks())
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("victims = %v, want none", victimNames(got))
+				}
+				return
+			}
+			if names := victimNames(got); !equalNames(names, tt.want) {
+				t.Errorf("victims = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreemptionVictimsOrderByStartTime(t *testing.T) {
+	m := newPreemptManager(t, "a")
+	now := time.Now().UTC()
+	runOn(m, "a", "older", 0, now.Add(-time.Hour))
+	runOn(m, "a", "newer", 0, now)
+
+	p := task.Task{ID: uuid.New(), Priority: 10, Cpu: 1}
+	got := victimNames(m.preemptionVictims(p, m.getNode("a"), m.GetTasks()))
+	if want := []string{"newer"}; !equalNames(got, want) {
+		t.Errorf("victims = %v, want %v", got, want)
+	}
+}
+
+func TestPreemptionVictimsSkipsPinnedTasks(t *testing.T) {
+	m := newPreemptManager(t, "a")
+	pinned := runOn(m, "a", "pinned", 0, time.Now())
+	pinned.Node = "a"
+	m.TaskDb.Put(pinned.ID.String(), pinned)
+	runOn(m, "a", "other", 0, time.Now().Add(-time.Hour))
+
+	p := task.Task{ID: uuid.New(), Priority: 10, Cpu: 2}
+	if got := m.preemptionVictims(p, m.getNode("a"), m.GetTasks()); got != nil {
+		t.Errorf("victims = %v, want none as a pinned task cannot be preempted", victimNames(got))
+	}
+}
+
+// Tasks already being stopped for another preemption are not preempted
+// again and count as gone.
+func TestPreemptionVictimsCountsTasksBeingPreempted(t *testing.T) {
+	m := newPreemptManager(t, "a")
+	stopping := runOn(m, "a", "stopping", 0, time.Now())
+	runOn(m, "a", "other", 0, time.Now())
+	m.preempting[stopping.ID] = true
+
+	p := task.Task{ID: uuid.New(), Priority: 10, Cpu: 1}
+	got := m.preemptionVictims(p, m.getNode("a"), m.GetTasks())
+	if got == nil || len(got) != 0 {
+		t.Errorf("victims = %v, want none while room is being made", got)
+	}
+
+	p.Cpu = 2
+	if names := victimNames(m.preemptionVictims(p, m.getNode("a"), m.GetTasks())); !equalNames(names, []string{"other"}) {
+		t.Errorf("victims = %v, want [other]", names)
+	}
+
+	// Once the task has been moved off the node it no longer counts.
+	m.unassignTask("a", stopping.ID)
+	if m.preempting[stopping.ID] {
+		t.Errorf("task is still being preempted after leaving its node")
+	}
+}
+
+func TestFewerVictims(t *testing.T) {
+	v := func(priorities ...int) []*task.Task {
+		var tasks []*task.Task
+		for _, p := range priorities {
+			tasks = append(tasks, &task.Task{Priority: p})
+		}
+		return tasks
+	}
+	tests := []struct {
+		name string
+		a, b []*task.Task
+		want bool
+	}{
+		{"lower highest priority", v(0, 0, 0), v(1), true},
+		{"higher highest priority", v(1), v(0, 0), false},
+		{"same priority, fewer", v(0), v(0, 0), true},
+		{"same priority, more", v(0, 0), v(0), false},
+		{"none beats some", []*task.Task{}, v(0), true},
+		{"some loses to none", v(0), []*task.Task{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fewerVictims(tt.a, tt.b); got != tt.want {
+				t.Errorf("fewerVictims = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A preempted task is held back so the task that preempted it is ready
+// first.
+func TestPreemptedTaskIsHeldBack(t *testing.T) {
+	m := newPreemptManager(t, "a")
+	victim := runOn(m, "a", "victim", 0, time.Now())
+	m.resubmitTask("a", victim, task.ReasonPreempted)
+
+	if te, ok := m.Pending.Dequeue(); ok {
+		t.Errorf("dequeued %s right after it was preempted", te.Task.Name)
+	}
+	if m.Pending.Len() != 1 {
+		t.Errorf("Len() = %d, want the preempted task to be queued", m.Pending.Len())
+	}
+	if n := m.getNode("a"); n.CpuAllocated != 0 {
+		t.Errorf("node still has %v cores allocated", n.CpuAllocated)
+	}
+}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// TaskQueue holds the task events waiting to be sent to workers. Events to
+// stop tasks come first, as they free resources, then the events of tasks
+// with a higher Priority. Events of the same priority keep their order.
+type TaskQueue struct {
+	mu    sync.Mutex
+	items queueItems
+	seq   int
+}
+
+type queueItem struct {
+	te    task.TaskEvent
+	ready time.Time // when the event may be dequeued
+	seq   int
+}
+
+func NewTaskQueue() *TaskQueue {
+	return &TaskQueue{}
+}
+
+// Enqueue adds an event to the queue.
+func (q *TaskQueue) Enqueue(te task.TaskEvent) {
+	q.EnqueueAfter(te, 0)
+}
+
+// EnqueueAfter adds an event that is held back for delay, letting other
+// events go ahead of one that cannot be handled yet.
+func (q *TaskQueue) EnqueueAfter(te task.TaskEvent, delay time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	heap.Push(&q.items, queueItem{te: te, ready: time.Now().Add(delay), seq: q.seq})
+}
+
+// Dequeue removes and returns the first event that is not held back.
+func (q *TaskQueue) Dequeue() (task.TaskEvent, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	var held []queueItem
+	defer func() {
+		for _, item := range held {
+			heap.Push(&q.items, item)
+		}
+	}()
+	for q.items.Len() > 0 {
+		item := heap.Pop(&q.items).(queueItem)
+		if item.ready.After(now) {
+			held = append(held, item)
+			continue
+		}
+		return item.te, true
+	}
+	return task.TaskEvent{}, false
+}
+
+// Len returns the number of events in the queue, including those held back.
+func (q *TaskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
+
+// queueItems implements heap.Interface.
+type queueItems []queueItem
+
+func (s queueItems) Len() int { return len(s) }
+
+func (s queueItems) Less(i, j int) bool {
+	si, sj := s[i].te.State == task.Completed, s[j].te.State == task.Completed
+	if si != sj {
+		return si
+	}
+	if pi, pj := s[i].te.Task.Priority, s[j].te.Task.Priority; pi != pj {
+		return pi > pj
+	}
+	return s[i].seq < s[j].seq
+}
+
+func (s queueItems) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *queueItems) Push(x any) { *s = append(*s, x.(queueItem)) }
+
+func (s *queueItems) Pop() any {
+	old := *s
+	item := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func queuedEvent(name string, state task.State, priority int) task.TaskEvent {
+	return task.TaskEvent{
+		ID:    uuid.New(),
+		State: state,
+		Task:  task.Task{ID: uuid.New(), Name: name, Priority: priority},
+	}
+}
+
+// drain dequeues every event that is not held back and returns their task
+// names in order.
+func drain(q *TaskQueue) []string {
+	var names []string
+	for {
+		te, ok := q.Dequeue()
+		if !ok {
+			return names
+		}
+		names = append(names, te.Task.Name)
+	}
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTaskQueueOrder(t *testing.T) {
+	q := NewTaskQueue()
+	q.Enqueue(queuedEvent("low", task.Scheduled, 0))
+	q.Enqueue(queuedEvent("high", task.Scheduled, 10))
+	q.Enqueue(queuedEvent("stop-low", task.Completed, 0))
+	q.Enqueue(queuedEvent("high-2", task.Scheduled, 10))
+	q.Enqueue(queuedEvent("negative", task.Scheduled, -5))
+	q.Enqueue(queuedEvent("low-2", task.Scheduled, 0))
+	q.Enqueue(queuedEvent("stop-high", task.Completed, 10))
+
+	want := []string{"stop-high", "stop-low", "high", "high-2", "low", "low-2", "negative"}
+	if got := drain(q); !equalNames(got, want) {
+		t.Errorf("dequeued %v, want %v", got, want)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after draining, want 0", q.Len())
+	}
+}
+
+func TestTaskQueueHeldEvents(t *testing.T) {
+	q := NewTaskQueue()
+	q.EnqueueAfter(queuedEvent("held", task.Scheduled, 10), 100*time.Millisecond)
+	q.Enqueue(queuedEvent("ready", task.Scheduled, 0))
+
+	if got, want := drain(q), []string{"ready"}; !equalNames(got, want) {
+		t.Errorf("dequeued %v, want %v", got, want)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want the held event to be counted", q.Len())
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Errorf("dequeued an event that is held back")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if got, want := drain(q), []string{"held"}; !equalNames(got, want) {
+		t.Errorf("dequeued %v once the delay passed, want %v", got, want)
+	}
+}
+
+func TestTaskQueueRequeuedEventGoesLast(t *testing.T) {
+	q := NewTaskQueue()
+	q.Enqueue(queuedEvent("first", task.Scheduled, 0))
+	q.Enqueue(queuedEvent("second", task.Scheduled, 0))
+
+	te, _ := q.Dequeue()
+	q.EnqueueAfter(te, 0)
+	q.Enqueue(queuedEvent("third", task.Scheduled, 0))
+
+	want := []string{"second", "first", "third"}
+	if got := drain(q); !equalNames(got, want) {
+		t.Errorf("dequeued %v, want %v", got, want)
+	}
+}
+
+func TestTaskQueueEmpty(t *testing.T) {
+	q := NewTaskQueue()
+	if te, ok := q.Dequeue(); ok {
+		t.Errorf("Dequeue() on an empty queue returned %v", te)
+	}
+}
@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/scheduler"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// RestartTasks restarts tasks that stopped running according to their
+// restart policy.
+func (m *Manager) RestartTasks() {
+	for {
+		log.Println("Checking for tasks to restart")
+		m.restartTasks()
+		log.Println("Task restarts completed")
+		log.Printf("Sleeping for %v\n", m.RestartInterval)
+		time.Sleep(m.RestartInterval)
+	}
+}
+
+// restartTasks puts tasks that stopped running and should be restarted
+// into CrashLoopBackOff, and queues those whose backoff has passed to be
+// scheduled again.
+func (m *Manager) restartTasks() {
+	now := time.Now().UTC()
+	for _, t := range m.GetTasks() {
+		switch {
+		case t.StopRequested:
+			m.restartMu.Lock()
+			delete(m.restartNodes, t.ID)
+			m.restartMu.Unlock()
+		case t.State == task.Pending && t.Reason == task.ReasonCrashLoopBackOff:
+			if !now.Before(t.NextRestart) {
+				m.restartTask(t, now)
+			}
+		case t.ShouldRestart():
+			m.backOffTask(t, now)
+		}
+	}
+}
+
+// backOffTask takes a task that stopped running off its node and records
+// when it is to be restarted. The node is remembered so a task that is not
+// pinned to a node can be restarted there, and so its logs can still be
+// read in the meantime.
+func (m *Manager) backOffTask(t *task.Task, now time.Time) {
+	reason := t.Reason
+	delay := t.BackOff(now)
+
+	w, _ := m.taskWorker(t.ID)
+	m.unassignTask(w, t.ID)
+	m.restartMu.Lock()
+	m.restartNodes[t.ID] = w
+	m.restartMu.Unlock()
+
+	m.TaskDb.Put(t.ID.String(), t)
+	log.Printf("Task %s on %s stopped (%s), restart %d in %v\n",
+		t.ID, w, reason, t.RestartCount+1, delay.Round(time.Second))
+}
+
+// restartTask queues a task whose backoff has passed to be scheduled again.
+func (m *Manager) restartTask(t *task.Task, now time.Time) {
+	t.RecordRestart(now)
+	t.Reason = ""
+	t.Error = ""
+	t.FinishTime = time.Time{}
+	m.TaskDb.Put(t.ID.String(), t)
+
+	te := task.TaskEvent{
+		ID:        uuid.New(),
+		State:     task.Running,
+		Timestamp: now,
+		Task:      *t,
+	}
+	m.AddTask(te)
+	log.Printf("Restarting task %s, restart %d\n", t.ID, t.RestartCount)
+}
+
+// restartNode returns the node a restarted task should run on: the node it
+// ran on before, or any other node once it has been restarted there too
+// often. It returns nil for tasks that are not being restarted.
+func (m *Manager) restartNode(t task.Task) (*node.Node, error) {
+	m.restartMu.Lock()
+	prev, ok := m.restartNodes[t.ID]
+	m.restartMu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	var n *node.Node
+	var err error
+	if t.RescheduleDue() {
+		n, err = m.selectWorker(t, m.nodesExcept(prev))
+		if err == nil && n.Name != prev {
+			log.Printf("Moving task %s from %s to %s after %d restarts in a row\n", t.ID, prev, n.Name, t.ConsecutiveRestarts-1)
+		}
+	} else if n = m.getNode(prev); n == nil || !canReturnTo(t, n) {
+		n, err = m.SelectWorker(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// canReturnTo reports whether a restarted task can go back to the node it
+// ran on.
+func canReturnTo(t task.Task, n *node.Node) bool {
+	return n.AcceptsTasks() && scheduler.Fits(t, n) == nil &&
+		scheduler.MatchesAffinity(t, n) && scheduler.ToleratesTaints(t, n)
+}
+
+// nodesExcept returns the worker nodes other than name, or all of them if
+// there is no other.
+func (m *Manager) nodesExcept(name string) []*node.Node {
+	all := m.nodes()
+	var nodes []*node.Node
+	for _, n := range all {
+		if n.Name != name {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		return all
+	}
+	return nodes
+}
+
+func removeTaskID(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
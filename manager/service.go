@@ -0,0 +1,445 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (m *Manager) GetServices() []*task.Service {
+	services, err := m.ServiceDb.List()
+	if err != nil {
+		log.Printf("error getting list of services: %v\n", err)
+		return nil
+	}
+	return services.([]*task.Service)
+}
+
+func (m *Manager) GetService(name string) (*task.Service, error) {
+	result, err := m.ServiceDb.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*task.Service), nil
+}
+
+// PutService creates or replaces a service. Changes to the replica count are
+// picked up by the next reconciliation; a changed template is recorded as a
+// new revision and rolled out over the existing tasks.
+func (m *Manager) PutService(s task.Service) error {
+	m.serviceMu.Lock()
+	defer m.serviceMu.Unlock()
+	return m.putService(s)
+}
+
+func (m *Manager) putService(s task.Service) error {
+	existing, err := m.GetService(s.Name)
+	if err == nil {
+		s.CreatedAt = existing.CreatedAt
+		s.RunningReplicas = existing.RunningReplicas
+		s.Revision = existing.Revision
+		s.Rollout = existing.Rollout
+		if sameTemplate(existing.Template, s.Template) {
+			s.UpdatedAt = time.Now().UTC()
+			return m.ServiceDb.Put(s.Name, &s)
+		}
+	} else {
+		s.CreatedAt = time.Now().UTC()
+	}
+
+	s.UpdatedAt = time.Now().UTC()
+	s.Revision++
+	rev := task.ServiceRevision{
+		Service:   s.Name,
+		Revision:  s.Revision,
+		Template:  s.Template,
+		CreatedAt: s.UpdatedAt,
+	}
+	err = m.RevisionDb.Put(revisionKey(s.Name, s.Revision), &rev)
+	if err != nil {
+		return err
+	}
+	s.Rollout = task.RolloutStatus{
+		Revision:  s.Revision,
+		State:     task.RolloutProgressing,
+		StartedAt: s.UpdatedAt,
+	}
+	log.Printf("Service %s is rolling out revision %d\n", s.Name, s.Revision)
+	return m.ServiceDb.Put(s.Name, &s)
+}
+
+func sameTemplate(a, b task.Task) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+func revisionKey(service string, revision int) string {
+	return fmt.Sprintf("%s/%d", service, revision)
+}
+
+// GetRevisions returns the recorded revisions of a service, oldest first.
+func (m *Manager) GetRevisions(name string) []*task.ServiceRevision {
+	result, err := m.RevisionDb.List()
+	if err != nil {
+		log.Printf("error getting list of revisions: %v\n", err)
+		return nil
+	}
+	var revisions []*task.ServiceRevision
+	for _, r := range result.([]*task.ServiceRevision) {
+		if r.Service == name {
+			revisions = append(revisions, r)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions
+}
+
+// UndoRollout rolls a service back to the template of an earlier revision,
+// the one before the current revision when revision is 0. Like any other
+// template change this creates a new revision.
+func (m *Manager) UndoRollout(name string, revision int) (*task.Service, error) {
+	m.serviceMu.Lock()
+	defer m.serviceMu.Unlock()
+	s, err := m.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *task.ServiceRevision
+	for _, r := range m.GetRevisions(name) {
+		if revision == 0 && r.Revision < s.Revision {
+			target = r
+		}
+		if revision != 0 && r.Revision == revision {
+			target = r
+		}
+	}
+	if target == nil {
+		if revision == 0 {
+			return nil, fmt.Errorf("service %s has no previous revision", name)
+		}
+		return nil, fmt.Errorf("service %s has no revision %d", name, revision)
+	}
+
+	updated := *s
+	updated.Template = target.Template
+	err = m.putService(updated)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Rolling service %s back to revision %d\n", name, target.Revision)
+	return m.GetService(name)
+}
+
+// ResumeRollout continues a rollout that was paused after a failure. Only
+// failures from then on pause it again.
+func (m *Manager) ResumeRollout(name string) (*task.Service, error) {
+	m.serviceMu.Lock()
+	defer m.serviceMu.Unlock()
+	s, err := m.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+	if s.Rollout.State != task.RolloutPaused {
+		return nil, fmt.Errorf("rollout of service %s is not paused", name)
+	}
+	s.Rollout.State = task.RolloutProgressing
+	s.Rollout.Message = ""
+	s.Rollout.StartedAt = time.Now().UTC()
+	err = m.ServiceDb.Put(s.Name, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DeleteService stops every task of the service and removes it.
+func (m *Manager) DeleteService(name string) error {
+	m.serviceMu.Lock()
+	defer m.serviceMu.Unlock()
+	s, err := m.GetService(name)
+	if err != nil {
+		return err
+	}
+	for _, t := range m.serviceTasks(s) {
+		if t.Active() {
+			m.requestStop(t)
+		}
+	}
+	for _, r := range m.GetRevisions(name) {
+		m.RevisionDb.Delete(revisionKey(r.Service, r.Revision))
+	}
+	return m.ServiceDb.Delete(name)
+}
+
+// rolloutHealthGrace is how long a new task may stay unready
+// during a rollout before the rollout is paused.
+const rolloutHealthGrace = 60 * time.Second
+
+func (m *Manager) ReconcileServices() {
+	for {
+		log.Println("Reconciling services")
+		m.reconcileServices()
+		log.Println("Service reconciliation completed")
+		log.Printf("Sleeping for %v\n", m.ReconcileInterval)
+		time.Sleep(m.ReconcileInterval)
+	}
+}
+
+func (m *Manager) reconcileServices() {
+	m.serviceMu.Lock()
+	defer m.serviceMu.Unlock()
+	for _, s := range m.GetServices() {
+		m.reconcileService(s)
+	}
+}
+
+// reconcileService compares the active tasks of a service with its desired
+// replica count, creating new tasks or stopping the most recent ones to
+// close the gap. Tasks that failed, including those lost with their worker,
+// no longer count as active and are replaced. While tasks of an older
+// revision remain, they are replaced step by step instead.
+func (m *Manager) reconcileService(s *task.Service) {
+	owner := task.Owner{Kind: task.OwnerService, Name: s.Name}
+	var current, old []*task.Task
+	running := 0
+	for _, t := range m.serviceTasks(s) {
+		if !t.Active() {
+			continue
+		}
+		if t.Owner == owner && t.Revision == s.Revision {
+			current = append(current, t)
+		} else {
+			old = append(old, t)
+		}
+		if t.State == task.Running {
+			running++
+		}
+	}
+
+	switch {
+	case s.Rollout.State == task.RolloutPaused:
+		log.Printf("Rollout of service %s is paused: %s\n", s.Name, s.Rollout.Message)
+	case len(old) > 0:
+		m.rolloutStep(s, current, old)
+	default:
+		m.scaleService(s, current)
+	}
+
+	if s.RunningReplicas != running {
+		s.RunningReplicas = running
+		m.ServiceDb.Put(s.Name, s)
+	}
+}
+
+func (m *Manager) scaleService(s *task.Service, active []*task.Task) {
+	owner := task.Owner{Kind: task.OwnerService, Name: s.Name}
+	diff := s.Replicas - len(active)
+	switch {
+	case diff > 0:
+		log.Printf("Service %s has %d of %d replicas, creating %d\n", s.Name, len(active), s.Replicas, diff)
+		for i := 0; i < diff; i++ {
+			m.createOwnedTask(owner, revisionTemplate(s))
+		}
+	case diff < 0:
+		log.Printf("Service %s has %d of %d replicas, stopping %d\n", s.Name, len(active), s.Replicas, -diff)
+		sort.Slice(active, func(i, j int) bool {
+			return active[i].StartTime.After(active[j].StartTime)
+		})
+		for _, t := range active[:-diff] {
+			m.requestStop(t)
+		}
+	}
+
+	if s.Rollout.State == task.RolloutProgressing && diff == 0 {
+		for _, t := range active {
+			if !m.taskAvailable(t) {
+				return
+			}
+		}
+		s.Rollout.State = task.RolloutComplete
+		s.Rollout.UpdatedReplicas = len(active)
+		s.Rollout.OldReplicas = 0
+		m.ServiceDb.Put(s.Name, s)
+		log.Printf("Rollout of revision %d of service %s is complete\n", s.Revision, s.Name)
+	}
+}
+
+// rolloutStep moves a service towards its current revision by creating new
+// tasks within MaxSurge and stopping old ones for as long as no more than
+// MaxUnavailable replicas are unavailable. New tasks only count as
+// available once they are running and are ready. The rollout
+// is paused when a new task fails without being restarted or stays unready
+// beyond rolloutHealthGrace.
+func (m *Manager) rolloutStep(s *task.Service, current, old []*task.Task) {
+	owner := task.Owner{Kind: task.OwnerService, Name: s.Name}
+	surge, unavailable := s.UpdateStrategy.Limits()
+	s.Rollout.State = task.RolloutProgressing
+
+	for _, t := range m.ownedTasks(owner) {
+		if t.Revision == s.Revision && t.State == task.Failed && !t.ShouldRestart() &&
+			t.FinishTime.After(s.Rollout.StartedAt) {
+			m.pauseRollout(s, fmt.Sprintf("task %s of revision %d failed: %s", t.ID, s.Revision, t.Reason))
+			return
+		}
+	}
+
+	available, updated := 0, 0
+	for _, t := range current {
+		if t.State != task.Running {
+			continue
+		}
+		err := m.checkRolloutHealth(*t)
+		if err == nil {
+			available++
+			updated++
+			continue
+		}
+		if time.Since(t.StartTime) > rolloutHealthGrace {
+			m.pauseRollout(s, fmt.Sprintf("task %s of revision %d is unhealthy: %v", t.ID, s.Revision, err))
+			return
+		}
+	}
+
+	// Old tasks that are not running yet can go right away, the running
+	// ones oldest last so that the longest serving tasks are kept longest.
+	sort.Slice(old, func(i, j int) bool {
+		ri, rj := old[i].State == task.Running, old[j].State == task.Running
+		if ri != rj {
+			return !ri
+		}
+		return old[i].StartTime.After(old[j].StartTime)
+	})
+	for _, t := range old {
+		if t.State == task.Running {
+			available++
+		}
+	}
+
+	create := s.Replicas + surge - len(current) - len(old)
+	if missing := s.Replicas - len(current); missing < create {
+		create = missing
+	}
+	for i := 0; i < create; i++ {
+		m.createOwnedTask(owner, revisionTemplate(s))
+	}
+
+	canStop := available - (s.Replicas - unavailable)
+	stopped := 0
+	for _, t := range old {
+		if t.State == task.Running {
+			if canStop <= 0 {
+				break
+			}
+			canStop--
+		}
+		m.requestStop(t)
+		stopped++
+	}
+
+	log.Printf("Service %s rolling out revision %d: %d updated, %d old, created %d, stopped %d\n",
+		s.Name, s.Revision, updated, len(old), max(create, 0), stopped)
+	s.Rollout.UpdatedReplicas = updated
+	s.Rollout.OldReplicas = len(old) - stopped
+	m.ServiceDb.Put(s.Name, s)
+}
+
+// checkRolloutHealth gates a rollout on the readiness of a task, as
+// reported by the probes its worker runs.
+func (m *Manager) checkRolloutHealth(t task.Task) error {
+	if !t.Ready {
+		return fmt.Errorf("task %s is not ready", t.ID)
+	}
+	return nil
+}
+
+func (m *Manager) taskAvailable(t *task.Task) bool {
+	return t.State == task.Running && m.checkRolloutHealth(*t) == nil
+}
+
+func (m *Manager) pauseRollout(s *task.Service, msg string) {
+	log.Printf("Pausing rollout of service %s: %s\n", s.Name, msg)
+	s.Rollout.State = task.RolloutPaused
+	s.Rollout.Message = msg
+	m.ServiceDb.Put(s.Name, s)
+}
+
+// serviceTasks returns the tasks selected by a service.
+func (m *Manager) serviceTasks(s *task.Service) []*task.Task {
+	var tasks []*task.Task
+	for _, t := range m.GetTasks() {
+		if s.Selects(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// ownedTasks returns the tasks the manager created for owner.
+func (m *Manager) ownedTasks(owner task.Owner) []*task.Task {
+	var tasks []*task.Task
+	for _, t := range m.GetTasks() {
+		if t.Owner == owner {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// createOwnedTask queues a new task built from template on behalf of owner.
+// The task is stored as Pending right away so that it is counted before a
+// worker has been selected for it.
+func (m *Manager) createOwnedTask(owner task.Owner, template task.Task) task.Task {
+	t := template
+	t.ID = uuid.New()
+	t.Name = fmt.Sprintf("%s-%s", owner.Name, t.ID.String()[:8])
+	t.State = task.Pending
+	t.Owner = owner
+	m.TaskDb.Put(t.ID.String(), &t)
+
+	te := task.TaskEvent{
+		ID:        uuid.New(),
+		State:     task.Running,
+		Timestamp: time.Now(),
+		Task:      t,
+	}
+	m.AddTask(te)
+	log.Printf("Created task %s for %s\n", t.ID, owner)
+	return t
+}
+
+// requestStop queues a stop for a task and marks it so it is no longer
+// counted as active while the worker stops it.
+func (m *Manager) requestStop(t *task.Task) {
+	t.StopRequested = true
+	m.TaskDb.Put(t.ID.String(), t)
+
+	te := task.TaskEvent{
+		ID:        uuid.New(),
+		State:     task.Completed,
+		Timestamp: time.Now(),
+	}
+	taskCopy := *t
+	taskCopy.State = task.Completed
+	te.Task = taskCopy
+	m.AddTask(te)
+	log.Printf("Added task event %v to stop task %v\n", te.ID, t.ID)
+}
+
+// revisionTemplate returns the template of a service stamped with its
+// current revision, so the tasks created from it can be told apart from
+// those of earlier revisions.
+func revisionTemplate(s *task.Service) task.Task {
+	t := s.Template
+	t.Revision = s.Revision
+	return t
+}
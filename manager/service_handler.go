@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (a *Api) PutServiceHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	s := task.Service{}
+	err := d.Decode(&s)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	if name := chi.URLParam(r, "name"); name != "" {
+		s.Name = name
+	}
+
+	err = task.ValidateService(s)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid service %s: %v", s.Name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.PutService(s)
+	if err != nil {
+		msg := fmt.Sprintf("Error storing service %s: %v", s.Name, err)
+		log.Println(msg)
+		w.WriteHeader(500)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 500, Message: msg})
+		return
+	}
+
+	log.Printf("Stored service %s with %d replicas\n", s.Name, s.Replicas)
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(s)
+}
+
+func (a *Api) GetServicesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetServices())
+}
+
+func (a *Api) GetServiceHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	s, err := a.Manager.GetService(name)
+	if err != nil {
+		log.Printf("No service with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(s)
+}
+
+func (a *Api) DeleteServiceHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.DeleteService(name)
+	if err != nil {
+		log.Printf("No service with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	log.Printf("Deleted service %s\n", name)
+	w.WriteHeader(204)
+}
+
+func (a *Api) GetRolloutHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	s, err := a.Manager.GetService(name)
+	if err != nil {
+		log.Printf("No service with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(s.Rollout)
+}
+
+func (a *Api) GetRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetRevisions(name))
+}
+
+// UndoRolloutHandler rolls a service back to the revision given by the
+// revision query parameter, or to the previous one without it.
+func (a *Api) UndoRolloutHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	revision := 0
+	if v := r.URL.Query().Get("revision"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			msg := fmt.Sprintf("Invalid revision %q", v)
+			log.Println(msg)
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+			return
+		}
+		revision = n
+	}
+
+	s, err := a.Manager.UndoRollout(name, revision)
+	if err != nil {
+		msg := fmt.Sprintf("Error undoing rollout of service %s: %v", name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(s.Rollout)
+}
+
+func (a *Api) ResumeRolloutHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	s, err := a.Manager.ResumeRollout(name)
+	if err != nil {
+		msg := fmt.Sprintf("Error resuming rollout of service %s: %v", name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(s.Rollout)
+}
@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/scheduler"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// TaintNode adds a taint to a node, replacing one with the same key and
+// effect. Tasks on the node that do not tolerate a NoExecute taint are
+// moved to other nodes in the background.
+func (m *Manager) TaintNode(name string, taint node.Taint) (*node.Node, error) {
+	n := m.updateNode(name, func(n *node.Node) {
+		taints := slices.DeleteFunc(slices.Clone(n.Taints), func(t node.Taint) bool {
+			return t.Key == taint.Key && t.Effect == taint.Effect
+		})
+		n.Taints = append(taints, taint)
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	log.Printf("Tainted node %s with %s\n", name, taint)
+
+	if taint.Effect == node.NoExecute {
+		go m.evictUntolerated(n)
+	}
+	return n, nil
+}
+
+// UntaintNode removes the taints with a key from a node, only those with
+// the given effect unless it is empty.
+func (m *Manager) UntaintNode(name string, key string, effect string) (*node.Node, error) {
+	removed := false
+	n := m.updateNode(name, func(n *node.Node) {
+		taints := slices.DeleteFunc(slices.Clone(n.Taints), func(t node.Taint) bool {
+			return t.Key == key && (effect == "" || t.Effect == effect)
+		})
+		removed = len(taints) < len(n.Taints)
+		n.Taints = taints
+	})
+	if n == nil {
+		return nil, fmt.Errorf("node %s is not registered", name)
+	}
+	if !removed {
+		if effect != "" {
+			key = fmt.Sprintf("%s:%s", key, effect)
+		}
+		return nil, fmt.Errorf("node %s has no taint %s", name, key)
+	}
+	log.Printf("Removed taint %s from node %s\n", key, name)
+	return n, nil
+}
+
+// evictUntolerated moves the tasks of a node that do not tolerate one of its
+// NoExecute taints to other nodes. Tasks pinned to the node cannot move and
+// are stopped.
+func (m *Manager) evictUntolerated(n *node.Node) {
+	for _, t := range m.GetTasks() {
+		if w, _ := m.taskWorker(t.ID); w != n.Name || !t.Active() || t.State == task.Pending {
+			continue
+		}
+		taints := scheduler.Untolerated(*t, n, node.NoExecute)
+		if len(taints) == 0 {
+			continue
+		}
+		if t.Node != "" {
+			log.Printf("Stopping task %s, it does not tolerate taint %s of node %s it is pinned to\n", t.ID, taints[0], n.Name)
+			m.requestStop(t)
+			continue
+		}
+		m.resubmitTask(n.Name, t, task.ReasonEvicted)
+		m.stopTask(n.Name, t.ID.String())
+		log.Printf("Evicting task %s from node %s, it does not tolerate taint %s\n", t.ID, n.Name, taints[0])
+	}
+}
@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// A worker registering again with a new NoExecute taint has the tasks that
+// do not tolerate it moved off, as when the node is tainted through the API.
+func TestRegisterNodeEvictsUntoleratedTasks(t *testing.T) {
+	// Nothing listens on the node, so asking it to stop tasks fails fast.
+	const addr = "127.0.0.1:1"
+	m := newPreemptManager(t, addr)
+	intolerant := runOn(m, addr, "intolerant", 0, time.Now())
+	tolerant := runOn(m, addr, "tolerant", 0, time.Now())
+	tolerant.Tolerations = []task.Toleration{{Key: "maintenance", Operator: "Exists"}}
+	m.TaskDb.Put(tolerant.ID.String(), tolerant)
+
+	taint := node.Taint{Key: "maintenance", Value: "true", Effect: node.NoExecute}
+	reg := node.Registration{Name: "worker", Address: addr, Cores: 2, Taints: []node.Taint{taint}}
+	if _, created := m.RegisterNode(reg); created {
+		t.Fatal("registering again created a new node")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := m.taskWorker(intolerant.ID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task that does not tolerate the new taint was not evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	got, _ := m.TaskDb.Get(intolerant.ID.String())
+	if r := got.(*task.Task).Reason; r != task.ReasonEvicted {
+		t.Errorf("reason = %q, want %q", r, task.ReasonEvicted)
+	}
+	if w, _ := m.taskWorker(tolerant.ID); w != addr {
+		t.Errorf("task that tolerates the taint was moved off the node")
+	}
+	if n := m.getNode(addr); len(n.Taints) != 1 || n.Taints[0] != taint {
+		t.Errorf("taints = %v, want [%v]", n.Taints, taint)
+	}
+}
@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (m *Manager) GetWorkflows() []*task.Workflow {
+	workflows, err := m.WorkflowDb.List()
+	if err != nil {
+		log.Printf("error getting list of workflows: %v\n", err)
+		return nil
+	}
+	return workflows.([]*task.Workflow)
+}
+
+func (m *Manager) GetWorkflow(name string) (*task.Workflow, error) {
+	result, err := m.WorkflowDb.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*task.Workflow), nil
+}
+
+// AddWorkflow stores a new workflow. Like jobs, workflows cannot be changed
+// once created.
+func (m *Manager) AddWorkflow(w task.Workflow) error {
+	m.workflowMu.Lock()
+	defer m.workflowMu.Unlock()
+	if _, err := m.GetWorkflow(w.Name); err == nil {
+		return fmt.Errorf("workflow %s already exists", w.Name)
+	}
+	for i := range w.Tasks {
+		w.Tasks[i].State = task.StepWaiting
+		w.Tasks[i].TaskID = uuid.Nil
+		w.Tasks[i].Reason = ""
+	}
+	w.State = task.WorkflowRunning
+	w.CreatedAt = time.Now().UTC()
+	return m.WorkflowDb.Put(w.Name, &w)
+}
+
+// DeleteWorkflow stops the running tasks of a workflow and removes it.
+func (m *Manager) DeleteWorkflow(name string) error {
+	m.workflowMu.Lock()
+	defer m.workflowMu.Unlock()
+	if _, err := m.GetWorkflow(name); err != nil {
+		return err
+	}
+	for _, t := range m.ownedTasks(task.Owner{Kind: task.OwnerWorkflow, Name: name}) {
+		if t.Active() {
+			m.requestStop(t)
+		}
+	}
+	return m.WorkflowDb.Delete(name)
+}
+
+func (m *Manager) ReconcileWorkflows() {
+	for {
+		log.Println("Reconciling workflows")
+		m.reconcileWorkflows()
+		log.Println("Workflow reconciliation completed")
+		log.Printf("Sleeping for %v\n", m.ReconcileInterval)
+		time.Sleep(m.ReconcileInterval)
+	}
+}
+
+func (m *Manager) reconcileWorkflows() {
+	m.workflowMu.Lock()
+	defer m.workflowMu.Unlock()
+	for _, w := range m.GetWorkflows() {
+		if !w.Finished() {
+			m.reconcileWorkflow(w)
+		}
+	}
+}
+
+// reconcileWorkflow records the outcome of started tasks, then starts the
+// waiting tasks whose dependencies all succeeded and skips those with a
+// failed or skipped dependency. Skipping repeats until nothing changes so
+// that a failure reaches every downstream task in one pass.
+func (m *Manager) reconcileWorkflow(w *task.Workflow) {
+	owner := task.Owner{Kind: task.OwnerWorkflow, Name: w.Name}
+	byName := make(map[string]*task.WorkflowTask)
+	for i := range w.Tasks {
+		byName[w.Tasks[i].Name] = &w.Tasks[i]
+	}
+
+	for i := range w.Tasks {
+		wt := &w.Tasks[i]
+		if wt.State != task.StepStarted {
+			continue
+		}
+		result, err := m.TaskDb.Get(wt.TaskID.String())
+		if err != nil {
+			log.Printf("Task %s of workflow %s is missing: %v\n", wt.Name, w.Name, err)
+			continue
+		}
+		t := result.(*task.Task)
+		switch {
+		case t.State == task.Completed && t.Reason == task.ReasonCompleted:
+			wt.State = task.StepSucceeded
+		case t.ShouldRestart():
+			// The task is about to be restarted, so the step is still
+			// running.
+		case t.State == task.Completed || t.State == task.Failed:
+			wt.State = task.StepFailed
+			wt.Reason = t.Reason
+			if wt.Reason == "" {
+				wt.Reason = task.ReasonFailed
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := range w.Tasks {
+			wt := &w.Tasks[i]
+			if wt.State != task.StepWaiting {
+				continue
+			}
+			ready := true
+			for _, d := range wt.DependsOn {
+				dep := byName[d]
+				if dep.State == task.StepFailed || dep.State == task.StepSkipped {
+					wt.State = task.StepSkipped
+					wt.Reason = fmt.Sprintf("dependency %s %s", d, dep.State)
+					changed = true
+					break
+				}
+				if dep.State != task.StepSucceeded {
+					ready = false
+				}
+			}
+			if wt.State == task.StepWaiting && ready {
+				t := m.createOwnedTask(owner, wt.Template)
+				wt.TaskID = t.ID
+				wt.State = task.StepStarted
+				log.Printf("Started task %s of workflow %s\n", wt.Name, w.Name)
+			}
+		}
+	}
+
+	done, failed := true, false
+	for _, wt := range w.Tasks {
+		if !wt.Done() {
+			done = false
+		}
+		if wt.State != task.StepSucceeded {
+			failed = true
+		}
+	}
+	if done {
+		w.State = task.WorkflowSucceeded
+		if failed {
+			w.State = task.WorkflowFailed
+		}
+		w.CompletionTime = time.Now().UTC()
+		log.Printf("Workflow %s %s\n", w.Name, w.State)
+	}
+	m.WorkflowDb.Put(w.Name, w)
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+func (a *Api) StartWorkflowHandler(w http.ResponseWriter, r *http.Request) {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	wf := task.Workflow{}
+	err := d.Decode(&wf)
+	if err != nil {
+		msg := fmt.Sprintf("Error unmarshalling body: %v\n", err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = task.ValidateWorkflow(wf)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid workflow %s: %v", wf.Name, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	err = a.Manager.AddWorkflow(wf)
+	if err != nil {
+		msg := fmt.Sprintf("Error adding workflow %s: %v", wf.Name, err)
+		log.Println(msg)
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 409, Message: msg})
+		return
+	}
+
+	stored, err := a.Manager.GetWorkflow(wf.Name)
+	if err != nil {
+		stored = &wf
+	}
+	log.Printf("Added workflow %s with %d tasks\n", stored.Name, len(stored.Tasks))
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(stored)
+}
+
+func (a *Api) GetWorkflowsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(a.Manager.GetWorkflows())
+}
+
+func (a *Api) GetWorkflowHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	wf, err := a.Manager.GetWorkflow(name)
+	if err != nil {
+		log.Printf("No workflow with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(wf)
+}
+
+func (a *Api) DeleteWorkflowHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	err := a.Manager.DeleteWorkflow(name)
+	if err != nil {
+		log.Printf("No workflow with name %v found", name)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	log.Printf("Deleted workflow %s\n", name)
+	w.WriteHeader(204)
+}
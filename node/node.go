@@ -7,30 +7,92 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/utsab818/my-orchestrator/stats"
 	"github.com/utsab818/my-orchestrator/utils"
 )
 
+// States of a node, derived from how recently the manager could reach it.
+const (
+	Ready    = "Ready"    // the last contact succeeded
+	NotReady = "NotReady" // the last contact failed
+	Lost     = "Lost"     // unreachable for longer than the grace period, its tasks were moved
+)
+
+// Scheduling states of a node, set by an operator.
+const (
+	Schedulable = "Schedulable" // new tasks may be placed on the node
+	Cordoned    = "Cordoned"    // no new tasks are placed on the node
+	Draining    = "Draining"    // the node's tasks are being moved to other nodes
+)
+
 type Node struct {
-	Name            string
-	Ip              string
-	Api             string
-	Cores           int
-	Memory          int
-	MemoryAllocated int
-	Disk            int
-	DiskAllocated   int
-	Role            string
-	TaskCount       int
-	Stats           stats.Stats
+	Name               string
+	Ip                 string
+	Api                string
+	Cores              int
+	CpuAllocated       float64
+	Memory             int                          // KiB
+	MemoryAllocated    int                          // KiB
+	Disk               int                          // bytes
+	DiskAllocated      int                          // bytes
+	HostPortsAllocated map[string]bool              // "<hostPort>/<proto>" bound by tasks on the node
+	TaskLabels         map[string]map[string]string // labels of the tasks placed on the node, by task ID
+	Role               string
+	TaskCount          int
+	Stats              stats.Stats
+	Labels             map[string]string
+	Taints             []Taint
+	WorkerName         string // name the worker registered with
+	State              string
+	Scheduling         string
+	LastContact        time.Time // last time the manager reached the node
+	ContactFailures    int       // failed contacts since then
+}
+
+// Registration is sent by a worker to join the manager's cluster. The
+// worker is known to the manager by its Address, which is also the name of
+// its node.
+type Registration struct {
+	Name    string
+	Address string // host:port of the worker API
+	Cores   int
+	Memory  int // KiB
+	Disk    int // bytes
+	Labels  map[string]string
+	Taints  []Taint
 }
 
 func NewNode(name string, api string, role string) *Node {
 	return &Node{
-		Name: name,
-		Api:  api,
-		Role: role,
+		Name:        name,
+		Api:         api,
+		Role:        role,
+		State:       NotReady,
+		Scheduling:  Schedulable,
+		LastContact: time.Now().UTC(),
+	}
+}
+
+// AcceptsTasks reports whether new tasks can be scheduled on the node.
+func (n *Node) AcceptsTasks() bool {
+	return n.State == Ready && n.Scheduling == Schedulable
+}
+
+// Contacted records a successful contact with the node.
+func (n *Node) Contacted() {
+	n.State = Ready
+	n.LastContact = time.Now().UTC()
+	n.ContactFailures = 0
+}
+
+// ContactFailed records a failed contact with the node. A lost node stays
+// lost until it is reached again.
+func (n *Node) ContactFailed() {
+	n.ContactFailures++
+	if n.State != Lost {
+		n.State = NotReady
 	}
 }
 
@@ -76,6 +138,9 @@ func (n *Node) GetStats() (*stats.Stats, error) {
 
 	n.Memory = int(stats.MemTotalKb())
 	n.Disk = int(stats.DiskTotal())
+	if stats.Cores > 0 {
+		n.Cores = stats.Cores
+	}
 
 	n.Stats = stats
 	return &n.Stats, nil
@@ -0,0 +1,50 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Effects of a taint on tasks that do not tolerate it.
+const (
+	NoSchedule       = "NoSchedule"       // the task is not placed on the node
+	PreferNoSchedule = "PreferNoSchedule" // the task is placed on the node only if no other fits
+	NoExecute        = "NoExecute"        // the task is not placed on the node and is moved off it
+)
+
+// Taint keeps tasks that do not tolerate it off a node.
+type Taint struct {
+	Key    string
+	Value  string
+	Effect string
+}
+
+func (t Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// ParseTaint parses a taint written as key=value:Effect or key:Effect.
+func ParseTaint(s string) (Taint, error) {
+	kv, effect, ok := strings.Cut(s, ":")
+	if !ok {
+		return Taint{}, fmt.Errorf("invalid taint %q, expected key=value:Effect", s)
+	}
+	key, value, _ := strings.Cut(kv, "=")
+	t := Taint{Key: key, Value: value, Effect: effect}
+	return t, ValidateTaint(t)
+}
+
+func ValidateTaint(t Taint) error {
+	if t.Key == "" {
+		return fmt.Errorf("invalid taint %q, Key is required", t)
+	}
+	switch t.Effect {
+	case NoSchedule, PreferNoSchedule, NoExecute:
+	default:
+		return fmt.Errorf("invalid taint %q, Effect must be %s, %s or %s", t, NoSchedule, PreferNoSchedule, NoExecute)
+	}
+	return nil
+}
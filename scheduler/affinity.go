@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// MatchesAffinity reports whether a node has the labels in the node
+// selector of a task and satisfies its required affinities.
+func MatchesAffinity(t task.Task, n *node.Node) bool {
+	for k, v := range t.NodeSelector {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	if t.Affinity == nil {
+		return true
+	}
+	if r := t.Affinity.NodeAffinity; r != nil {
+		for _, e := range r.Required {
+			if !e.Matches(n.Labels) {
+				return false
+			}
+		}
+	}
+	if r := t.Affinity.TaskAffinity; r != nil {
+		for _, e := range r.Required {
+			if !hostsMatchingTask(t, n, e) {
+				return false
+			}
+		}
+	}
+	if r := t.Affinity.TaskAntiAffinity; r != nil {
+		for _, e := range r.Required {
+			if hostsMatchingTask(t, n, e) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// affinityPenalty returns the share of the weight of the preferred
+// affinities of a task that a node does not satisfy, from 0 to 1.
+func affinityPenalty(t task.Task, n *node.Node) float64 {
+	if t.Affinity == nil {
+		return 0
+	}
+	var total, missed int
+	if r := t.Affinity.NodeAffinity; r != nil {
+		for _, e := range r.Preferred {
+			total += e.Weight
+			if !e.Matches(n.Labels) {
+				missed += e.Weight
+			}
+		}
+	}
+	if r := t.Affinity.TaskAffinity; r != nil {
+		for _, e := range r.Preferred {
+			total += e.Weight
+			if !hostsMatchingTask(t, n, e.LabelExpression) {
+				missed += e.Weight
+			}
+		}
+	}
+	if r := t.Affinity.TaskAntiAffinity; r != nil {
+		for _, e := range r.Preferred {
+			total += e.Weight
+			if hostsMatchingTask(t, n, e.LabelExpression) {
+				missed += e.Weight
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(missed) / float64(total)
+}
+
+// hostsMatchingTask reports whether a task other than t whose labels match
+// the expression has been placed on the node.
+func hostsMatchingTask(t task.Task, n *node.Node, e task.LabelExpression) bool {
+	for id, labels := range n.TaskLabels {
+		if id != t.ID.String() && e.Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
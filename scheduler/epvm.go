@@ -23,19 +23,11 @@ type Epvm struct {
 	Name string
 }
 
-// Here, we are only checking for disk space
+// Nodes without room for the task are left out by Filter before the
+// scheduler is asked, so only the affinities and tolerations of the task
+// are checked.
 func (e *Epvm) SelectCandidateNodes(t task.Task, nodes []*node.Node) []*node.Node {
-	var candidates []*node.Node
-	for node := range nodes {
-		if checkDisk(t, nodes[node].Disk-nodes[node].DiskAllocated) {
-			candidates = append(candidates, nodes[node])
-		}
-	}
-	return candidates
-}
-
-func checkDisk(t task.Task, diskAvailable int) bool {
-	return t.Disk <= diskAvailable
+	return candidateNodes(t, nodes)
 }
 
 func (e *Epvm) Score(t task.Task, nodes []*node.Node) map[string]float64 {
@@ -63,7 +55,7 @@ func (e *Epvm) Score(t task.Task, nodes []*node.Node) map[string]float64 {
 			math.Pow(LIEB, (float64(node.TaskCount+1))/maxJobs) -
 			math.Pow(LIEB, cpuLoad) -
 			math.Pow(LIEB, float64(node.TaskCount)/float64(maxJobs))
-		nodeScores[node.Name] = memCost + cpuCost
+		nodeScores[node.Name] = memCost + cpuCost + preferencePenalty(t, node)
 	}
 	return nodeScores
 }
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// Fits returns why a task does not fit on a node: not enough unallocated
+// CPU, memory or disk, or a host port already bound by another task.
+// Capacity the node has not reported is not checked.
+func Fits(t task.Task, n *node.Node) error {
+	if t.Cpu > 0 && n.Cores > 0 && n.CpuAllocated+t.Cpu > float64(n.Cores) {
+		return fmt.Errorf("insufficient cpu")
+	}
+	if t.Memory > 0 && n.Memory > 0 && n.MemoryAllocated+t.Memory/1024 > n.Memory {
+		return fmt.Errorf("insufficient memory")
+	}
+	if disk := t.DiskRequest(); disk > 0 && n.Disk > 0 && n.DiskAllocated+disk > n.Disk {
+		return fmt.Errorf("insufficient disk")
+	}
+	for _, p := range t.BoundHostPorts() {
+		if n.HostPortsAllocated[p] {
+			return fmt.Errorf("host port %s in use", p)
+		}
+	}
+	return nil
+}
+
+// Filter returns the nodes a task fits on. When it fits on none, the error
+// counts the reasons the nodes were rejected for.
+func Filter(t task.Task, nodes []*node.Node) ([]*node.Node, error) {
+	var fit []*node.Node
+	reasons := make(map[string]int)
+	for _, n := range nodes {
+		if err := Fits(t, n); err != nil {
+			reasons[err.Error()]++
+			continue
+		}
+		fit = append(fit, n)
+	}
+	if len(fit) > 0 {
+		return fit, nil
+	}
+
+	var msgs []string
+	for r, count := range reasons {
+		msgs = append(msgs, fmt.Sprintf("%d %s", count, r))
+	}
+	sort.Strings(msgs)
+	return nil, fmt.Errorf("0/%d nodes fit task %v: %s", len(nodes), t.ID, strings.Join(msgs, ", "))
+}
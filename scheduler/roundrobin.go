@@ -18,7 +18,7 @@ type RoundRobin struct {
 }
 
 func (r *RoundRobin) SelectCandidateNodes(t task.Task, nodes []*node.Node) []*node.Node {
-	return nodes
+	return candidateNodes(t, nodes)
 }
 
 func (r *RoundRobin) Score(t task.Task, nodes []*node.Node) map[string]float64 {
@@ -32,12 +32,14 @@ func (r *RoundRobin) Score(t task.Task, nodes []*node.Node) map[string]float64 {
 		r.LastWorker = 0
 	}
 
+	// Nodes the task prefers are picked over the others.
 	for idx, node := range nodes {
 		if idx == newWorker {
 			nodeScores[node.Name] = 0.1
 		} else {
 			nodeScores[node.Name] = 1.0
 		}
+		nodeScores[node.Name] += preferencePenalty(t, node)
 	}
 	return nodeScores
 }
@@ -10,3 +10,26 @@ type Scheduler interface {
 	Score(t task.Task, nodes []*node.Node) map[string]float64
 	Pick(scores map[string]float64, candidates []*node.Node) *node.Node
 }
+
+// preferenceWeight is what a node the task would rather not run on adds to
+// its score, enough to outweigh its load.
+const preferenceWeight = 10.0
+
+// candidateNodes returns the nodes that satisfy the node selector and the
+// required affinities of a task and whose taints it tolerates.
+func candidateNodes(t task.Task, nodes []*node.Node) []*node.Node {
+	var candidates []*node.Node
+	for _, n := range nodes {
+		if MatchesAffinity(t, n) && ToleratesTaints(t, n) {
+			candidates = append(candidates, n)
+		}
+	}
+	return candidates
+}
+
+// preferencePenalty scores how much a node goes against the preferred
+// affinities of a task and the share of its PreferNoSchedule taints the task
+// does not tolerate.
+func preferencePenalty(t task.Task, n *node.Node) float64 {
+	return preferenceWeight * (affinityPenalty(t, n) + taintPenalty(t, n))
+}
@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// ToleratesTaints reports whether a task tolerates every NoSchedule and
+// NoExecute taint of a node.
+func ToleratesTaints(t task.Task, n *node.Node) bool {
+	return len(Untolerated(t, n, node.NoSchedule)) == 0 && len(Untolerated(t, n, node.NoExecute)) == 0
+}
+
+// Untolerated returns the taints of a node with the given effect that a task
+// does not tolerate.
+func Untolerated(t task.Task, n *node.Node, effect string) []node.Taint {
+	var taints []node.Taint
+	for _, taint := range n.Taints {
+		if taint.Effect == effect && !t.Tolerates(taint.Key, taint.Value, taint.Effect) {
+			taints = append(taints, taint)
+		}
+	}
+	return taints
+}
+
+// taintPenalty returns the share of the PreferNoSchedule taints of a node a
+// task does not tolerate, from 0 to 1.
+func taintPenalty(t task.Task, n *node.Node) float64 {
+	var total int
+	for _, taint := range n.Taints {
+		if taint.Effect == node.PreferNoSchedule {
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(len(Untolerated(t, n, node.PreferNoSchedule))) / float64(total)
+}
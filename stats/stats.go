@@ -2,6 +2,7 @@ package stats
 
 import (
 	"log"
+	"runtime"
 
 	"github.com/c9s/goprocinfo/linux"
 )
@@ -11,6 +12,7 @@ type Stats struct {
 	DiskStats *linux.Disk
 	CpuStats  *linux.CPUStat
 	LoadStats *linux.LoadAvg
+	Cores     int
 }
 
 // memory related metrics
@@ -61,6 +63,7 @@ func GetStats() *Stats {
 		DiskStats: GetDiskInfo(),
 		CpuStats:  GetCpuStats(),
 		LoadStats: GetLoadAvg(),
+		Cores:     runtime.NumCPU(),
 	}
 }
 
@@ -271,3 +271,18 @@ func (e *EventStore) List() (interface{}, error) {
 	}
 	return events, nil
 }
+
+// For delete method we will be using Read-Write transaction
+func (t *TaskStore) Delete(key string) error {
+	return t.Db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(t.Bucket))
+		return b.Delete([]byte(key))
+	})
+}
+
+func (e *EventStore) Delete(key string) error {
+	return e.Db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(e.Bucket))
+		return b.Delete([]byte(key))
+	})
+}
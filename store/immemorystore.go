@@ -2,17 +2,24 @@ package store
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/utsab818/my-orchestrator/task"
 )
 
 // **************************************************
 
+// The in-memory stores are used by several goroutines of the manager and
+// worker at once. Like the bolt stores, they keep a copy of what is put and
+// hand out copies, so callers never share a task.
+
 type InMemoryTaskStore struct {
+	mu sync.RWMutex
 	Db map[string]*task.Task
 }
 
 type InMemoryTaskEventStore struct {
+	mu sync.RWMutex
 	Db map[string]*task.TaskEvent
 }
 
@@ -37,30 +44,48 @@ func (i *InMemoryTaskStore) Put(key string, value any) error {
 	if !ok {
 		return fmt.Errorf("value %v is not a task.Task type", value)
 	}
-	i.Db[key] = t
+	c := *t
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.Db[key] = &c
 	return nil
 }
 
 func (i *InMemoryTaskStore) Get(key string) (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	t, ok := i.Db[key]
 	if !ok {
 		return nil, fmt.Errorf("task with key %s does not exist", key)
 	}
-	return t, nil
+	c := *t
+	return &c, nil
 }
 
 func (i *InMemoryTaskStore) List() (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	var tasks []*task.Task
 	for _, t := range i.Db {
-		tasks = append(tasks, t)
+		c := *t
+		tasks = append(tasks, &c)
 	}
 	return tasks, nil
 }
 
 func (i *InMemoryTaskStore) Count() (int, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	return len(i.Db), nil
 }
 
+func (i *InMemoryTaskStore) Delete(key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.Db, key)
+	return nil
+}
+
 // for InMemoryTaskEventStore
 
 func (i *InMemoryTaskEventStore) Put(key string, value any) error {
@@ -69,26 +94,44 @@ func (i *InMemoryTaskEventStore) Put(key string, value any) error {
 		return fmt.Errorf("value %v is not a task.TaskEvent type", value)
 	}
 
-	i.Db[key] = e
+	c := *e
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.Db[key] = &c
 	return nil
 }
 
 func (i *InMemoryTaskEventStore) Get(key string) (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	e, ok := i.Db[key]
 	if !ok {
 		return nil, fmt.Errorf("task event with key %s does not exist", key)
 	}
-	return e, nil
+	c := *e
+	return &c, nil
 }
 
 func (i *InMemoryTaskEventStore) List() (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	var events []*task.TaskEvent
 	for _, e := range i.Db {
-		events = append(events, e)
+		c := *e
+		events = append(events, &c)
 	}
 	return events, nil
 }
 
 func (i *InMemoryTaskEventStore) Count() (int, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	return len(i.Db), nil
 }
+
+func (i *InMemoryTaskEventStore) Delete(key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.Db, key)
+	return nil
+}
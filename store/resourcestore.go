@@ -0,0 +1,177 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// InMemoryStore and BoltStore hold any manager resource other than tasks and
+// task events (services, jobs, ...). Values are stored as *T and List
+// returns []*T. Both keep a copy of what is put and hand out copies.
+
+type InMemoryStore[T any] struct {
+	mu sync.RWMutex
+	Db map[string]*T
+}
+
+func NewInMemoryStore[T any]() *InMemoryStore[T] {
+	return &InMemoryStore[T]{
+		Db: make(map[string]*T),
+	}
+}
+
+func (i *InMemoryStore[T]) Put(key string, value any) error {
+	v, ok := value.(*T)
+	if !ok {
+		return fmt.Errorf("value %v is not a %T type", value, new(T))
+	}
+	c := *v
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.Db[key] = &c
+	return nil
+}
+
+func (i *InMemoryStore[T]) Get(key string) (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	v, ok := i.Db[key]
+	if !ok {
+		return nil, fmt.Errorf("item with key %s does not exist", key)
+	}
+	c := *v
+	return &c, nil
+}
+
+func (i *InMemoryStore[T]) List() (any, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var items []*T
+	for _, v := range i.Db {
+		c := *v
+		items = append(items, &c)
+	}
+	return items, nil
+}
+
+func (i *InMemoryStore[T]) Count() (int, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.Db), nil
+}
+
+func (i *InMemoryStore[T]) Delete(key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.Db, key)
+	return nil
+}
+
+type BoltStore[T any] struct {
+	Db       *bolt.DB
+	DbFile   string
+	FileMode os.FileMode
+	Bucket   string
+}
+
+func NewBoltStore[T any](file string, mode os.FileMode, bucket string) (*BoltStore[T], error) {
+	db, err := bolt.Open(file, mode, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %v", file)
+	}
+
+	s := BoltStore[T]{
+		DbFile:   file,
+		FileMode: mode,
+		Db:       db,
+		Bucket:   bucket,
+	}
+
+	err = s.Db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bucket %s: %s", bucket, err)
+	}
+	return &s, nil
+}
+
+func (s *BoltStore[T]) Close() {
+	s.Db.Close()
+}
+
+func (s *BoltStore[T]) Put(key string, value any) error {
+	v, ok := value.(*T)
+	if !ok {
+		return fmt.Errorf("value %v is not a %T type", value, new(T))
+	}
+	return s.Db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.Bucket))
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), buf)
+	})
+}
+
+func (s *BoltStore[T]) Get(key string) (any, error) {
+	var v T
+	err := s.Db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.Bucket))
+		data := b.Get([]byte(key))
+		if data == nil {
+			return fmt.Errorf("item %v not found", key)
+		}
+		return json.Unmarshal(data, &v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (s *BoltStore[T]) List() (any, error) {
+	var items []*T
+	err := s.Db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.Bucket))
+		return b.ForEach(func(k, data []byte) error {
+			var v T
+			err := json.Unmarshal(data, &v)
+			if err != nil {
+				return err
+			}
+			items = append(items, &v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *BoltStore[T]) Count() (int, error) {
+	count := 0
+	err := s.Db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.Bucket))
+		count = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+	return count, nil
+}
+
+func (s *BoltStore[T]) Delete(key string) error {
+	return s.Db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.Bucket))
+		return b.Delete([]byte(key))
+	})
+}
@@ -6,4 +6,5 @@ type Store interface {
 	Get(key string) (any, error)
 	List() (any, error)
 	Count() (int, error)
+	Delete(key string) error
 }
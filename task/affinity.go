@@ -0,0 +1,124 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Operators of a label expression.
+const (
+	OpIn           = "In"           // the label is set to one of Values
+	OpNotIn        = "NotIn"        // the label is not set to any of Values
+	OpExists       = "Exists"       // the label is set
+	OpDoesNotExist = "DoesNotExist" // the label is not set
+)
+
+// Affinity constrains the nodes a task is placed on. NodeAffinity is
+// matched against the labels of a node, TaskAffinity and TaskAntiAffinity
+// against the labels of the tasks already placed on it, so replicas can
+// be kept together or apart.
+type Affinity struct {
+	NodeAffinity     *AffinityRules
+	TaskAffinity     *AffinityRules
+	TaskAntiAffinity *AffinityRules
+}
+
+// AffinityRules are the expressions a node has to satisfy and those it is
+// preferred to satisfy, each weighted from 1 to 100.
+type AffinityRules struct {
+	Required  []LabelExpression
+	Preferred []WeightedLabelExpression
+}
+
+type LabelExpression struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+type WeightedLabelExpression struct {
+	Weight int
+	LabelExpression
+}
+
+// Matches reports whether labels satisfy the expression.
+func (e LabelExpression) Matches(labels map[string]string) bool {
+	v, ok := labels[e.Key]
+	switch e.Operator {
+	case OpIn:
+		return ok && slices.Contains(e.Values, v)
+	case OpNotIn:
+		return !ok || !slices.Contains(e.Values, v)
+	case OpExists:
+		return ok
+	case OpDoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+// Merge returns rules with the expressions of both r and other.
+func (r *AffinityRules) Merge(other *AffinityRules) *AffinityRules {
+	if r == nil {
+		return other
+	}
+	if other == nil {
+		return r
+	}
+	return &AffinityRules{
+		Required:  slices.Concat(r.Required, other.Required),
+		Preferred: slices.Concat(r.Preferred, other.Preferred),
+	}
+}
+
+func validateAffinity(a *Affinity) error {
+	var errs []error
+	rules := []struct {
+		name string
+		r    *AffinityRules
+	}{
+		{"NodeAffinity", a.NodeAffinity},
+		{"TaskAffinity", a.TaskAffinity},
+		{"TaskAntiAffinity", a.TaskAntiAffinity},
+	}
+	for _, rule := range rules {
+		name, r := rule.name, rule.r
+		if r == nil {
+			continue
+		}
+		for _, e := range r.Required {
+			if err := validateLabelExpression(e); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s expression: %w", name, err))
+			}
+		}
+		for _, e := range r.Preferred {
+			if err := validateLabelExpression(e.LabelExpression); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s expression: %w", name, err))
+			}
+			if e.Weight < 1 || e.Weight > 100 {
+				errs = append(errs, fmt.Errorf("%s weight must be between 1 and 100", name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateLabelExpression(e LabelExpression) error {
+	if e.Key == "" {
+		return errors.New("Key is required")
+	}
+	switch e.Operator {
+	case OpIn, OpNotIn:
+		if len(e.Values) == 0 {
+			return fmt.Errorf("%s needs at least one value", e.Operator)
+		}
+	case OpExists, OpDoesNotExist:
+		if len(e.Values) > 0 {
+			return fmt.Errorf("%s takes no values", e.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown Operator %q, expected %s, %s, %s or %s", e.Operator, OpIn, OpNotIn, OpExists, OpDoesNotExist)
+	}
+	return nil
+}
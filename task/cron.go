@@ -0,0 +1,236 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard cron expression with five fields:
+// minute, hour, day of month, month and day of week. Each field accepts *,
+// numbers, ranges (1-5), steps (*/15, 0-30/5) and lists of those, months
+// and days of the week also accept their three letter names. The @hourly,
+// @daily, @midnight, @weekly, @monthly, @yearly and @annually shorthands
+// are supported too.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// As in cron, when both day fields are restricted a day matching
+	// either one is enough.
+	domStar, dowStar bool
+	// Schedules with a restricted hour run once at a time skipped or
+	// repeated by a daylight saving change, others follow the clock.
+	hourStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronShorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func ParseCron(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronShorthands[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron schedule %q must have 5 fields, got %d", spec, len(fields))
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron schedule %q: %v", cronFields[i].name, spec, err)
+		}
+		bits[i] = b
+	}
+	// Sunday can be written as 0 or 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &CronSchedule{
+		minute:   bits[0],
+		hour:     bits[1],
+		dom:      bits[2],
+		month:    bits[3],
+		dow:      bits[4],
+		domStar:  strings.HasPrefix(fields[2], "*"),
+		dowStar:  strings.HasPrefix(fields[4], "*"),
+		hourStar: strings.HasPrefix(fields[1], "*"),
+	}, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(a, f); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(b, f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := cronValue(rng, f)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (s *CronSchedule) matches(t time.Time) bool {
+	return s.month&(1<<uint(t.Month())) != 0 && s.dayMatches(t) &&
+		s.hour&(1<<uint(t.Hour())) != 0 && s.minute&(1<<uint(t.Minute())) != 0
+}
+
+// Next returns the first time after t matching the schedule, in the
+// location of t. It returns the zero time if there is none within five
+// years, as with a 30th of February.
+//
+// A time skipped when clocks go forward runs as soon as they have, and a
+// time repeated when they go back runs only once, unless the hour field
+// is * and the schedule follows the clock.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		var next time.Time
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			next = startOfHour(t.Year(), t.Month()+1, 1, 0, loc)
+		case !s.dayMatches(t):
+			next = startOfHour(t.Year(), t.Month(), t.Day()+1, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			next = startOfHour(t.Year(), t.Month(), t.Day(), t.Hour()+1, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			next = t.Add(time.Minute)
+		case !s.hourStar && repeated(t):
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+		if !s.hourStar && s.skippedBetween(t, next) {
+			return next
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// skippedBetween reports whether clocks went forward between t and next
+// over a time the schedule matches. Every other time between them has
+// already been ruled out.
+func (s *CronSchedule) skippedBetween(t, next time.Time) bool {
+	end := wallClock(next)
+	for w := wallClock(t).Add(next.Sub(t)); w.Before(end); w = w.Add(time.Minute) {
+		if s.matches(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock returns the date and time t shows, as a time in UTC, which has
+// no daylight saving changes.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// startOfHour returns the start of an hour in loc or, when clocks go
+// forward over it, the time they go forward to. time.Date would go back
+// instead, which stops Next from moving on.
+func startOfHour(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, 0, 0, 0, loc)
+	want := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	if got := wallClock(t); got.Before(want) {
+		t = t.Add(want.Sub(got))
+	}
+	return t
+}
+
+// repeated reports whether the clock showed the time of t once before, as
+// it does for an hour after clocks go back.
+func repeated(t time.Time) bool {
+	_, off := t.Zone()
+	_, earlierOff := t.Add(-time.Hour).Zone()
+	shift := time.Duration(earlierOff-off) * time.Second
+	return shift > 0 && wallClock(t.Add(-shift)).Equal(wallClock(t))
+}
@@ -0,0 +1,152 @@
+package task
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+const cronLayout = "2006-01-02 15:04 -0700"
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	tm, err := time.Parse(cronLayout, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		after    string
+		want     string // empty when there is no next time
+	}{
+		{"step", "*/15 * * * *", "2024-01-01 00:00 +0000", "2024-01-01 00:15 +0000"},
+		{"range with step", "0-30/10 9 * * *", "2024-01-01 09:25 +0000", "2024-01-01 09:30 +0000"},
+		{"range with step ends", "0-30/10 9 * * *", "2024-01-01 09:31 +0000", "2024-01-02 09:00 +0000"},
+		{"value with step", "50/5 * * * *", "2024-01-01 00:51 +0000", "2024-01-01 00:55 +0000"},
+		{"list", "5,10-12 * * * *", "2024-01-01 00:10 +0000", "2024-01-01 00:11 +0000"},
+		{"seconds are dropped", "* * * * *", "2024-01-01 00:00 +0000", "2024-01-01 00:01 +0000"},
+		{"month name", "0 0 1 jan *", "2024-01-01 00:00 +0000", "2025-01-01 00:00 +0000"},
+		{"month name in capitals", "0 0 1 MAR *", "2024-01-01 00:00 +0000", "2024-03-01 00:00 +0000"},
+		{"day name range", "0 12 * * mon-fri", "2024-01-06 00:00 +0000", "2024-01-08 12:00 +0000"},
+		{"sunday as 7", "0 0 * * 7", "2024-01-01 00:00 +0000", "2024-01-07 00:00 +0000"},
+		{"sunday as 0", "0 0 * * 0", "2024-01-01 00:00 +0000", "2024-01-07 00:00 +0000"},
+		{"hourly", "@hourly", "2024-01-01 00:30 +0000", "2024-01-01 01:00 +0000"},
+		{"weekly", "@weekly", "2024-01-01 00:00 +0000", "2024-01-07 00:00 +0000"},
+		{"yearly", "@yearly", "2024-06-01 00:00 +0000", "2025-01-01 00:00 +0000"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00 +0000", "2028-02-29 00:00 +0000"},
+		{"no such day", "0 0 30 2 *", "2024-01-01 00:00 +0000", ""},
+		{"location of after", "0 9 * * *", "2024-01-01 10:00 +0100", "2024-01-02 09:00 +0100"},
+
+		// When both day fields are restricted either one matching is enough.
+		{"day of week or month, by week", "0 0 13 * fri", "2024-01-01 00:00 +0000", "2024-01-05 00:00 +0000"},
+		{"day of week or month, by month", "0 0 13 * fri", "2024-01-12 00:00 +0000", "2024-01-13 00:00 +0000"},
+		{"day of month only", "0 0 13 * *", "2024-01-01 00:00 +0000", "2024-01-13 00:00 +0000"},
+		{"day of week only", "0 0 * * fri", "2024-01-06 00:00 +0000", "2024-01-12 00:00 +0000"},
+		// A day field starting with * is not a restriction, so both apply.
+		{"stepped day of month and week", "0 0 */2 * fri", "2024-01-05 00:00 +0000", "2024-01-19 00:00 +0000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.schedule, err)
+			}
+			after := mustTime(t, tt.after)
+			got := s.Next(after)
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want none", tt.after, got.Format(cronLayout))
+				}
+				return
+			}
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got.Format(cronLayout), tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks in Santiago go forward at midnight.
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		schedule string
+		after    string
+		want     string
+	}{
+		// Clocks in New York go from 2:00 to 3:00 on 10 March 2024.
+		{"skipped time runs once clocks went forward", newYork, "30 2 * * *", "2024-03-10 00:00 -0500", "2024-03-10 03:00 -0400"},
+		{"skipped time runs at its time the next day", newYork, "30 2 * * *", "2024-03-10 03:00 -0400", "2024-03-11 02:30 -0400"},
+		{"later hour on the day clocks go forward", newYork, "0 5 * * *", "2024-03-10 00:30 -0500", "2024-03-10 05:00 -0400"},
+		{"every hour skips the missing hour", newYork, "0 * * * *", "2024-03-10 01:30 -0500", "2024-03-10 03:00 -0400"},
+		{"skipped midnight", santiago, "0 0 * * *", "2024-09-07 12:00 -0400", "2024-09-08 01:00 -0300"},
+
+		// Clocks in New York go from 2:00 back to 1:00 on 3 November 2024.
+		{"repeated time runs first", newYork, "30 1 * * *", "2024-11-03 00:00 -0400", "2024-11-03 01:30 -0400"},
+		{"repeated time runs once", newYork, "30 1 * * *", "2024-11-03 01:30 -0400", "2024-11-04 01:30 -0500"},
+		{"every hour follows the clock", newYork, "*/30 * * * *", "2024-11-03 01:30 -0400", "2024-11-03 01:00 -0500"},
+		{"hour after the repeated one", newYork, "0 2 * * *", "2024-11-03 01:30 -0400", "2024-11-03 02:00 -0500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.schedule, err)
+			}
+			got := s.Next(mustTime(t, tt.after).In(tt.loc))
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got.Format(cronLayout), tt.want)
+			}
+			if got.Location() != tt.loc {
+				t.Errorf("Next returned a time in %v, want %v", got.Location(), tt.loc)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-2-3 * * * *",
+		"x * * * *",
+		"mon * * * *",
+		"* * * jan-foo *",
+		"* * * * sun,funday",
+		"1,,2 * * * *",
+	}
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseCron(spec); err == nil {
+				t.Errorf("ParseCron(%q) succeeded, want an error", spec)
+			}
+		})
+	}
+}
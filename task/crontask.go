@@ -0,0 +1,67 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const OwnerCron = "cron"
+
+// Concurrency policies of a cron task, deciding what happens when a run is
+// due while the task of the previous run is still active.
+const (
+	ConcurrencyAllow   = "Allow"   // start the new run alongside
+	ConcurrencyForbid  = "Forbid"  // skip the new run
+	ConcurrencyReplace = "Replace" // stop the previous run and start the new one
+)
+
+var ConcurrencyPolicies = []string{ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace}
+
+// CronTask asks the manager to start a task from Template on every
+// activation of Schedule, evaluated in TimeZone (the manager's local time
+// zone when empty). Only the latest SuccessfulHistoryLimit completed and
+// FailedHistoryLimit failed tasks are kept, 3 and 1 when left at zero.
+type CronTask struct {
+	Name                   string
+	Schedule               string
+	TimeZone               string
+	ConcurrencyPolicy      string
+	SuccessfulHistoryLimit int
+	FailedHistoryLimit     int
+	Template               Task
+	LastScheduleTime       time.Time
+	NextScheduleTime       time.Time
+	CreatedAt              time.Time
+}
+
+// Location returns the time zone the schedule is evaluated in.
+func (c *CronTask) Location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.TimeZone)
+}
+
+func ValidateCronTask(c CronTask) error {
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, errors.New("Name is required"))
+	}
+	if _, err := ParseCron(c.Schedule); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.Location(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid TimeZone %q: %v", c.TimeZone, err))
+	}
+	if c.ConcurrencyPolicy != "" && !containsString(ConcurrencyPolicies, c.ConcurrencyPolicy) {
+		errs = append(errs, fmt.Errorf("ConcurrencyPolicy %q must be one of %v", c.ConcurrencyPolicy, ConcurrencyPolicies))
+	}
+	if c.SuccessfulHistoryLimit < 0 || c.FailedHistoryLimit < 0 {
+		errs = append(errs, errors.New("SuccessfulHistoryLimit and FailedHistoryLimit must not be negative"))
+	}
+	if err := Validate(c.Template); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Template: %w", err))
+	}
+	return errors.Join(errs...)
+}
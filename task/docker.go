@@ -2,37 +2,45 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"log"
 	"math"
-	"os"
+	"syscall"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 )
 
 type Config struct {
-	Name          string
-	AttachStdin   bool
-	AttachStdout  bool
-	AttachStderr  bool
-	ExposedPorts  nat.PortSet
-	Cmd           []string
-	Image         string
-	Cpu           float64
-	Memory        int64
-	Disk          int64
-	Env           []string
-	RestartPolicy string
+	Name            string
+	AttachStdin     bool
+	AttachStdout    bool
+	AttachStderr    bool
+	ExposedPorts    nat.PortSet
+	PortBindings    nat.PortMap
+	NetworkMode     string
+	Cmd             []string
+	Entrypoint      []string
+	WorkingDir      string
+	User            string
+	Labels          map[string]string
+	Image           string
+	ImagePullPolicy string
+	RegistryAuth    string // base64 encoded credentials for the image's registry
+	Cpu             float64
+	Memory          int64
+	Disk            int64
+	Env             []string
+	Mounts          []mount.Mount
 }
 
 type Docker struct {
-	Client      *client.Client
-	Config      Config
-	ContainerId string
+	Client *client.Client
 }
 
 type DockerResult struct {
@@ -43,92 +51,186 @@ type DockerResult struct {
 }
 
 func NewConfig(t *Task) Config {
+	exposedPorts, portBindings := NewPortConfig(t.ExposedPorts, t.PortBindings)
 	return Config{
-		Name:          t.Name,
-		Image:         t.Image,
-		Memory:        int64(t.Memory),
-		Disk:          int64(t.Disk),
-		RestartPolicy: t.RestartPolicy,
+		Name:            t.Name,
+		Image:           t.Image,
+		ImagePullPolicy: t.ImagePullPolicy,
+		Cmd:             t.Cmd,
+		Entrypoint:      t.Entrypoint,
+		WorkingDir:      t.WorkingDir,
+		User:            t.User,
+		Labels:          t.Labels,
+		Env:             t.Env,
+		Cpu:             t.Cpu,
+		Memory:          int64(t.Memory),
+		Disk:            int64(t.Disk),
+		ExposedPorts:    exposedPorts,
+		PortBindings:    portBindings,
+		NetworkMode:     t.NetworkMode,
+		Mounts:          NewMounts(t.Volumes),
+	}
+}
+
+func NewMounts(volumes []Volume) []mount.Mount {
+	var mounts []mount.Mount
+	for _, v := range volumes {
+		m := mount.Mount{
+			Type:     mount.Type(v.Type),
+			Source:   v.Source,
+			Target:   v.Target,
+			ReadOnly: v.ReadOnly,
+		}
+		if v.Type == VolumeTypeTmpfs && v.Size > 0 {
+			m.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: int64(v.Size)}
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+// NewPortConfig turns the "<port>/<proto>": "<hostPort>" bindings of a task
+// into a docker port map. Every bound port is also added to the exposed
+// ports, since docker only publishes ports that are exposed.
+func NewPortConfig(exposed nat.PortSet, bindings map[string]string) (nat.PortSet, nat.PortMap) {
+	ports := nat.PortSet{}
+	for p := range exposed {
+		ports[p] = struct{}{}
+	}
+
+	var pm nat.PortMap
+	for k, hostPort := range bindings {
+		proto, port := nat.SplitProtoPort(k)
+		p, err := nat.NewPort(proto, port)
+		if err != nil {
+			log.Printf("Ignoring invalid port binding %s: %v\n", k, err)
+			continue
+		}
+		if pm == nil {
+			pm = nat.PortMap{}
+		}
+		ports[p] = struct{}{}
+		pm[p] = []nat.PortBinding{{HostPort: hostPort}}
+	}
+	return ports, pm
+}
+
+// BoundHostPorts returns the host ports the task binds, as "<hostPort>/<proto>".
+// Bindings without a host port get a random one and are left out.
+func (t *Task) BoundHostPorts() []string {
+	var bound []string
+	for k, hostPort := range t.PortBindings {
+		if hostPort == "" || hostPort == "0" {
+			continue
+		}
+		proto, _ := nat.SplitProtoPort(k)
+		bound = append(bound, hostPort+"/"+proto)
 	}
+	return bound
 }
 
-func NewDocker(c Config) *Docker {
-	client, _ := client.NewClientWithOpts(client.FromEnv)
+func NewDocker() (*Docker, error) {
+	client, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
 	return &Docker{
 		Client: client,
-		Config: c,
-	}
+	}, nil
 }
 
-func (d *Docker) Run() DockerResult {
+func (d *Docker) Run(c Config) DockerResult {
 	ctx := context.Background()
-	reader, err := d.Client.ImagePull(
-		ctx, d.Config.Image, image.PullOptions{})
+	err := d.pullImage(ctx, c)
 	if err != nil {
-		log.Printf("Error pulling image %s: %v\n", d.Config.Image, err)
+		log.Printf("Error pulling image %s: %v\n", c.Image, err)
 		return DockerResult{Error: err}
 	}
-	io.Copy(os.Stdout, reader)
-
-	rp := container.RestartPolicy{
-		Name: container.RestartPolicyMode(d.Config.RestartPolicy),
-	}
 
 	r := container.Resources{
-		Memory:   d.Config.Memory,
-		NanoCPUs: int64(d.Config.Cpu * math.Pow(10, 9)),
+		Memory:   c.Memory,
+		NanoCPUs: int64(c.Cpu * math.Pow(10, 9)),
 	}
 
 	cc := container.Config{
-		Image:        d.Config.Image,
+		Image:        c.Image,
 		Tty:          false,
-		Env:          d.Config.Env,
-		ExposedPorts: d.Config.ExposedPorts,
+		Cmd:          c.Cmd,
+		Entrypoint:   c.Entrypoint,
+		WorkingDir:   c.WorkingDir,
+		User:         c.User,
+		Labels:       c.Labels,
+		Env:          c.Env,
+		ExposedPorts: c.ExposedPorts,
 	}
 
+	// Explicit bindings take precedence; without them every exposed port is
+	// published on a random host port. Containers joining the network of
+	// another container cannot publish ports of their own. Docker never
+	// restarts containers; the manager restarts tasks by their policy.
+	nm := container.NetworkMode(c.NetworkMode)
 	hc := container.HostConfig{
-		RestartPolicy:   rp,
 		Resources:       r,
-		PublishAllPorts: true,
+		NetworkMode:     nm,
+		PortBindings:    c.PortBindings,
+		PublishAllPorts: len(c.PortBindings) == 0 && !nm.IsContainer(),
+		Mounts:          c.Mounts,
 	}
 
-	resp, err := d.Client.ContainerCreate(ctx, &cc, &hc, nil, nil, d.Config.Name)
-	if err != nil {
-		log.Printf("Error creating container using image %s: %v\n", d.Config.Image, err)
-		return DockerResult{Error: err}
+	// A task restarted on the same node keeps its name, so the exited
+	// container of its last run is removed first. A running container with
+	// the name is left alone and the create fails.
+	if c.Name != "" {
+		old, err := d.Client.ContainerInspect(ctx, c.Name)
+		if err == nil && !old.State.Running {
+			err = d.Client.ContainerRemove(ctx, old.ID, container.RemoveOptions{RemoveVolumes: true})
+			if err != nil {
+				log.Printf("Error removing exited container %s: %v\n", old.ID, err)
+			}
+		}
 	}
 
-	err = d.Client.ContainerStart(ctx, resp.ID, container.StartOptions{})
+	resp, err := d.Client.ContainerCreate(ctx, &cc, &hc, nil, nil, c.Name)
 	if err != nil {
-		log.Printf("Error starting container %s: %v\n", resp.ID, err)
+		log.Printf("Error creating container using image %s: %v\n", c.Image, err)
 		return DockerResult{Error: err}
 	}
 
-	d.ContainerId = resp.ID
-
-	out, err := d.Client.ContainerLogs(
-		ctx,
-		resp.ID,
-		container.LogsOptions{ShowStdout: true, ShowStderr: true})
-
+	err = d.Client.ContainerStart(ctx, resp.ID, container.StartOptions{})
 	if err != nil {
-		log.Printf("Error getting logs for container %s: %v\n", resp.ID, err)
+		log.Printf("Error starting container %s: %v\n", resp.ID, err)
 		return DockerResult{Error: err}
 	}
 
-	stdcopy.StdCopy(os.Stdout, os.Stderr, out)
 	return DockerResult{ContainerId: resp.ID, Action: "start", Result: "success"}
 }
 
-func (d *Docker) Stop(id string) DockerResult {
+func (d *Docker) Stop(id string, opts StopOptions) DockerResult {
 	log.Printf("Attempting to stop container %v", id)
 	ctx := context.Background()
-	err := d.Client.ContainerStop(ctx, id, container.StopOptions{})
+	so := container.StopOptions{Signal: opts.Signal}
+	if opts.Timeout > 0 {
+		so.Timeout = &opts.Timeout
+	}
+	err := d.Client.ContainerStop(ctx, id, so)
 	if err != nil {
 		log.Printf("Error stopping container %s: %v\n", id, err)
 		return DockerResult{Error: err}
 	}
 
+	// Docker falls back to SIGKILL once the timeout expires, which shows up
+	// as exit code 137 unless SIGKILL was the requested signal.
+	stopResult := StopGraceful
+	resp, err := d.Client.ContainerInspect(ctx, id)
+	if err == nil {
+		sig, _ := ParseSignal(opts.Signal)
+		if resp.State.ExitCode == 128+int(syscall.SIGKILL) && sig != syscall.SIGKILL {
+			stopResult = StopKilled
+		}
+	}
+
+	// RemoveVolumes only removes anonymous volumes, named volumes are left
+	// to the worker to remove according to their policy.
 	err = d.Client.ContainerRemove(ctx, id, container.RemoveOptions{
 		RemoveVolumes: true,
 		RemoveLinks:   false,
@@ -140,5 +242,90 @@ func (d *Docker) Stop(id string) DockerResult {
 		return DockerResult{Error: err}
 	}
 
-	return DockerResult{Action: "stop", Result: "success", Error: nil}
+	return DockerResult{Action: "stop", Result: stopResult, Error: nil}
+}
+
+func (d *Docker) RemoveVolume(name string) error {
+	ctx := context.Background()
+	err := d.Client.VolumeRemove(ctx, name, false)
+	if err != nil {
+		log.Printf("Error removing volume %s: %v\n", name, err)
+		return err
+	}
+	return nil
+}
+
+func (d *Docker) Logs(ctx context.Context, id string, opts LogOptions, stdout, stderr io.Writer) error {
+	out, err := d.Client.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: opts.Stdout,
+		ShowStderr: opts.Stderr,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+		Since:      opts.Since,
+	})
+	if err != nil {
+		log.Printf("Error getting logs for container %s: %v\n", id, err)
+		return err
+	}
+	defer out.Close()
+
+	_, err = stdcopy.StdCopy(stdout, stderr, out)
+	return err
+}
+
+func (d *Docker) Stats(id string) DockerStatsResponse {
+	ctx := context.Background()
+	resp, err := d.Client.ContainerStatsOneShot(ctx, id)
+	if err != nil {
+		log.Printf("Error getting stats for container %s: %v\n", id, err)
+		return DockerStatsResponse{Error: err}
+	}
+	defer resp.Body.Close()
+
+	var s types.StatsJSON
+	err = json.NewDecoder(resp.Body).Decode(&s)
+	if err != nil {
+		log.Printf("Error decoding stats for container %s: %v\n", id, err)
+		return DockerStatsResponse{Error: err}
+	}
+	return DockerStatsResponse{Stats: &s}
+}
+
+func (d *Docker) Exec(ctx context.Context, id string, opts ExecOptions) (*ExecSession, error) {
+	ec := types.ExecConfig{
+		Tty:          opts.Tty,
+		AttachStdin:  opts.Stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          opts.Cmd,
+	}
+	if opts.Tty && opts.Height > 0 && opts.Width > 0 {
+		ec.ConsoleSize = &[2]uint{opts.Height, opts.Width}
+	}
+
+	resp, err := d.Client.ContainerExecCreate(ctx, id, ec)
+	if err != nil {
+		log.Printf("Error creating exec in container %s: %v\n", id, err)
+		return nil, err
+	}
+
+	hr, err := d.Client.ContainerExecAttach(ctx, resp.ID, types.ExecStartCheck{
+		Tty:         opts.Tty,
+		ConsoleSize: ec.ConsoleSize,
+	})
+	if err != nil {
+		log.Printf("Error attaching to exec %s in container %s: %v\n", resp.ID, id, err)
+		return nil, err
+	}
+	return &ExecSession{ID: resp.ID, Conn: hr.Conn, Reader: hr.Reader}, nil
+}
+
+func (d *Docker) ExecInspect(execID string) (ExecResult, error) {
+	ctx := context.Background()
+	resp, err := d.Client.ContainerExecInspect(ctx, execID)
+	if err != nil {
+		log.Printf("Error inspecting exec %s: %v\n", execID, err)
+		return ExecResult{}, err
+	}
+	return ExecResult{ID: resp.ExecID, Running: resp.Running, ExitCode: resp.ExitCode}, nil
 }
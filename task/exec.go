@@ -0,0 +1,455 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/google/uuid"
+)
+
+const (
+	cgroupRoot        = "/sys/fs/cgroup"
+	cgroupParent      = "my-orchestrator"
+	cgroupCpuPeriod   = 100000
+	ExecStopTimeout   = 10 * time.Second
+	execFollowPolling = 500 * time.Millisecond
+)
+
+// ExecProcess is a task running as a plain child process of the worker.
+type ExecProcess struct {
+	ID         string
+	Config     Config
+	Cmd        *exec.Cmd
+	StdoutPath string
+	StderrPath string
+	Cgroup     string
+	Status     string
+	ExitCode   int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	done       chan struct{}
+}
+
+// Exec runs Task.Cmd, after the Entrypoint if one is set, directly on the
+// worker instead of inside a container. The process runs as Task.User if
+// one is set, in its own process group and with only the Env of the task.
+// Output is written to files under Dir, created when the first task runs,
+// and, when cgroup v2 is available, the Memory and Cpu limits of the task
+// are enforced through it.
+type Exec struct {
+	mu        sync.Mutex
+	Dir       string
+	Processes map[string]*ExecProcess
+}
+
+func NewExec(dir string) (*Exec, error) {
+	if dir == "" {
+		return nil, errors.New("exec driver requires a log directory")
+	}
+	return &Exec{
+		Dir:       dir,
+		Processes: make(map[string]*ExecProcess),
+	}, nil
+}
+
+func (e *Exec) Run(c Config) DockerResult {
+	args := append(append([]string{}, c.Entrypoint...), c.Cmd...)
+	if len(args) == 0 {
+		err := errors.New("exec driver requires a command")
+		log.Printf("Error starting task %s: %v\n", c.Name, err)
+		return DockerResult{Error: err}
+	}
+	attr := &syscall.SysProcAttr{Setpgid: true}
+	if c.User != "" {
+		cred, err := execCredential(c.User)
+		if err != nil {
+			log.Printf("Error starting task %s: %v\n", c.Name, err)
+			return DockerResult{Error: err}
+		}
+		attr.Credential = cred
+	}
+
+	p := &ExecProcess{
+		ID:     uuid.New().String(),
+		Config: c,
+		done:   make(chan struct{}),
+	}
+	p.StdoutPath = filepath.Join(e.Dir, fmt.Sprintf("%s.stdout", p.ID))
+	p.StderrPath = filepath.Join(e.Dir, fmt.Sprintf("%s.stderr", p.ID))
+
+	err := os.MkdirAll(e.Dir, 0700)
+	if err != nil {
+		log.Printf("Error creating log directory %s: %v\n", e.Dir, err)
+		return DockerResult{Error: err}
+	}
+	stdout, err := os.Create(p.StdoutPath)
+	if err != nil {
+		log.Printf("Error creating stdout file for task %s: %v\n", c.Name, err)
+		return DockerResult{Error: err}
+	}
+	stderr, err := os.Create(p.StderrPath)
+	if err != nil {
+		stdout.Close()
+		log.Printf("Error creating stderr file for task %s: %v\n", c.Name, err)
+		return DockerResult{Error: err}
+	}
+
+	p.Cmd = exec.Command(args[0], args[1:]...)
+	// A nil Env would hand the worker's environment to the task.
+	p.Cmd.Env = append([]string{}, c.Env...)
+	p.Cmd.Dir = c.WorkingDir
+	p.Cmd.SysProcAttr = attr
+	p.Cmd.Stdout = stdout
+	p.Cmd.Stderr = stderr
+
+	err = p.Cmd.Start()
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		log.Printf("Error starting command %v for task %s: %v\n", args, c.Name, err)
+		return DockerResult{Error: err}
+	}
+	p.Status = "running"
+	p.StartedAt = time.Now().UTC()
+
+	cg, err := createCgroup(p.ID, c, p.Cmd.Process.Pid)
+	if err != nil {
+		log.Printf("Not enforcing resource limits for task %s: %v\n", c.Name, err)
+	}
+	p.Cgroup = cg
+
+	e.mu.Lock()
+	e.Processes[p.ID] = p
+	e.mu.Unlock()
+
+	go func() {
+		err := p.Cmd.Wait()
+		stdout.Close()
+		stderr.Close()
+
+		e.mu.Lock()
+		p.Status = "exited"
+		p.FinishedAt = time.Now().UTC()
+		p.ExitCode = p.Cmd.ProcessState.ExitCode()
+		if ws, ok := p.Cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			p.ExitCode = 128 + int(ws.Signal())
+		}
+		e.mu.Unlock()
+		if err != nil {
+			log.Printf("Process %d for task %s exited: %v\n", p.Cmd.Process.Pid, c.Name, err)
+		}
+		if p.Cgroup != "" {
+			os.Remove(p.Cgroup)
+		}
+		close(p.done)
+	}()
+
+	return DockerResult{ContainerId: p.ID, Action: "start", Result: "success"}
+}
+
+// execCredential returns the credential of a user given as name or uid,
+// optionally followed by a colon and a group name or gid. Without a group
+// the primary group of the user is used, or gid 0 for a uid unknown to the
+// worker.
+func execCredential(spec string) (*syscall.Credential, error) {
+	name, group, hasGroup := strings.Cut(spec, ":")
+	var uid, gid uint64
+	u, err := user.Lookup(name)
+	if err != nil {
+		u, err = user.LookupId(name)
+	}
+	switch {
+	case err == nil:
+		uid, _ = strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ = strconv.ParseUint(u.Gid, 10, 32)
+	default:
+		uid, err = strconv.ParseUint(name, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unknown user %q", name)
+		}
+	}
+
+	if hasGroup {
+		g, err := user.LookupGroup(group)
+		if err == nil {
+			gid, _ = strconv.ParseUint(g.Gid, 10, 32)
+		} else if gid, err = strconv.ParseUint(group, 10, 32); err != nil {
+			return nil, fmt.Errorf("unknown group %q", group)
+		}
+	}
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
+
+// createCgroup puts pid into a dedicated cgroup v2 group limited to the
+// memory and cpu requested by the task. The process is started before it is
+// moved into the group, so limits apply from that point on.
+func createCgroup(id string, c Config, pid int) (string, error) {
+	if c.Memory == 0 && c.Cpu == 0 {
+		return "", nil
+	}
+	_, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers"))
+	if err != nil {
+		return "", errors.New("cgroup v2 is not available")
+	}
+
+	parent := filepath.Join(cgroupRoot, cgroupParent)
+	err = os.MkdirAll(parent, 0755)
+	if err != nil {
+		return "", err
+	}
+	err = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu"), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(parent, id)
+	err = os.Mkdir(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Memory > 0 {
+		err = os.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(c.Memory, 10)), 0644)
+		if err != nil {
+			os.Remove(dir)
+			return "", err
+		}
+	}
+	if c.Cpu > 0 {
+		quota := int64(c.Cpu * cgroupCpuPeriod)
+		err = os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%d %d", quota, cgroupCpuPeriod)), 0644)
+		if err != nil {
+			os.Remove(dir)
+			return "", err
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+	if err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func (e *Exec) process(id string) (*ExecProcess, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.Processes[id]
+	if !ok {
+		return nil, fmt.Errorf("no such process: %s", id)
+	}
+	return p, nil
+}
+
+// Stop sends the stop signal (SIGTERM by default) to the process group of
+// the process and falls back to SIGKILL if the process has not exited
+// within the timeout, ExecStopTimeout by default. Whatever is left of the
+// group once the process has exited is then killed.
+func (e *Exec) Stop(id string, opts StopOptions) DockerResult {
+	log.Printf("Attempting to stop process %v", id)
+	p, err := e.process(id)
+	if err != nil {
+		log.Printf("Error stopping process %s: %v\n", id, err)
+		return DockerResult{Error: err}
+	}
+
+	sig, err := ParseSignal(opts.Signal)
+	if err != nil {
+		return DockerResult{Error: err}
+	}
+	timeout := ExecStopTimeout
+	if opts.Timeout > 0 {
+		timeout = time.Duration(opts.Timeout) * time.Second
+	}
+
+	// The process was started as the leader of its own group.
+	pgid := p.Cmd.Process.Pid
+	stopResult := StopGraceful
+	select {
+	case <-p.done:
+	default:
+		err = syscall.Kill(-pgid, sig)
+		if err != nil {
+			log.Printf("Error sending %v to process %s: %v\n", sig, id, err)
+		}
+		select {
+		case <-p.done:
+		case <-time.After(timeout):
+			log.Printf("Process %s did not exit after %v, killing it\n", id, timeout)
+			syscall.Kill(-pgid, syscall.SIGKILL)
+			<-p.done
+			stopResult = StopKilled
+		}
+		syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+
+	e.mu.Lock()
+	delete(e.Processes, id)
+	e.mu.Unlock()
+	return DockerResult{Action: "stop", Result: stopResult, Error: nil}
+}
+
+func (e *Exec) Inspect(id string) DockerInspectResponse {
+	p, err := e.process(id)
+	if err != nil {
+		log.Printf("Error inspecting process %s\n", err)
+		return DockerInspectResponse{Error: err}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var finishedAt string
+	if !p.FinishedAt.IsZero() {
+		finishedAt = p.FinishedAt.Format(time.RFC3339Nano)
+	}
+
+	resp := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:      p.ID,
+			Name:    "/" + p.Config.Name,
+			Path:    p.Cmd.Args[0],
+			Args:    p.Cmd.Args[1:],
+			LogPath: p.StdoutPath,
+			Driver:  "exec",
+			State: &types.ContainerState{
+				Status:     p.Status,
+				Running:    p.Status == "running",
+				Pid:        p.Cmd.Process.Pid,
+				ExitCode:   p.ExitCode,
+				StartedAt:  p.StartedAt.Format(time.RFC3339Nano),
+				FinishedAt: finishedAt,
+			},
+		},
+		NetworkSettings: &types.NetworkSettings{},
+	}
+	return DockerInspectResponse{Container: &resp}
+}
+
+// Logs copies the captured output of a process. Since is not supported as
+// the files carry no timestamps.
+func (e *Exec) Logs(ctx context.Context, id string, opts LogOptions, stdout, stderr io.Writer) error {
+	p, err := e.process(id)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 2)
+	n := 0
+	if opts.Stdout {
+		n++
+		go func() { errCh <- copyLog(ctx, p, p.StdoutPath, opts, stdout) }()
+	}
+	if opts.Stderr {
+		n++
+		go func() { errCh <- copyLog(ctx, p, p.StderrPath, opts, stderr) }()
+	}
+
+	for i := 0; i < n; i++ {
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyLog(ctx context.Context, p *ExecProcess, path string, opts LogOptions, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = seekTail(f, opts.Tail)
+	if err != nil {
+		return err
+	}
+
+	for {
+		_, err = io.Copy(w, f)
+		if err != nil || !opts.Follow {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.done:
+			_, err = io.Copy(w, f)
+			return err
+		case <-time.After(execFollowPolling):
+		}
+	}
+}
+
+// seekTail positions f at the start of the last n lines, where n is the
+// Tail value of LogOptions. An empty value or "all" leaves f untouched.
+func seekTail(f *os.File, tail string) error {
+	if tail == "" || tail == "all" {
+		return nil
+	}
+	n, err := strconv.Atoi(tail)
+	if err != nil {
+		return fmt.Errorf("invalid tail value %q", tail)
+	}
+
+	var offsets []int64
+	var offset int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			offsets = append(offsets, offset)
+			offset += int64(len(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	start := offset
+	if n < len(offsets) {
+		if n > 0 {
+			start = offsets[len(offsets)-n]
+		}
+	} else if len(offsets) > 0 {
+		start = offsets[0]
+	}
+	_, err = f.Seek(start, io.SeekStart)
+	return err
+}
+
+func (e *Exec) Stats(id string) DockerStatsResponse {
+	p, err := e.process(id)
+	if err != nil {
+		return DockerStatsResponse{Error: err}
+	}
+
+	s := types.StatsJSON{
+		Name: "/" + p.Config.Name,
+		ID:   p.ID,
+	}
+	s.Read = time.Now().UTC()
+	s.MemoryStats.Limit = uint64(p.Config.Memory)
+	if p.Cgroup != "" {
+		data, err := os.ReadFile(filepath.Join(p.Cgroup, "memory.current"))
+		if err == nil {
+			usage, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+			s.MemoryStats.Usage = usage
+		}
+	}
+	return DockerStatsResponse{Stats: &s}
+}
@@ -0,0 +1,181 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runExec runs c on a new exec driver and waits for the process to exit.
+func runExec(t *testing.T, c Config) (*Exec, string) {
+	t.Helper()
+	e, err := NewExec(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := e.Run(c)
+	if res.Error != nil {
+		t.Fatalf("Run: %v", res.Error)
+	}
+	return e, res.ContainerId
+}
+
+func execOutput(t *testing.T, e *Exec, id string) string {
+	t.Helper()
+	p, err := e.process(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-p.done
+	out, err := os.ReadFile(p.StdoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestExecEnvironment(t *testing.T) {
+	t.Setenv("WORKER_SECRET", "leaked")
+	e, id := runExec(t, Config{Name: "env", Cmd: []string{"/usr/bin/env"}, Env: []string{"A=1"}})
+	if out := execOutput(t, e, id); out != "A=1\n" {
+		t.Errorf("environment of the task = %q, want only A=1", out)
+	}
+}
+
+func TestExecEntrypoint(t *testing.T) {
+	e, id := runExec(t, Config{Name: "echo", Entrypoint: []string{"/bin/echo", "hello"}, Cmd: []string{"world"}})
+	if out := execOutput(t, e, id); out != "hello world\n" {
+		t.Errorf("output = %q, want %q", out, "hello world\n")
+	}
+}
+
+func TestExecUnknownUser(t *testing.T) {
+	e, err := NewExec(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := e.Run(Config{Name: "user", Cmd: []string{"/bin/true"}, User: "no-such-user"}); res.Error == nil {
+		t.Errorf("Run succeeded as an unknown user")
+	}
+}
+
+func TestExecCredential(t *testing.T) {
+	tests := []struct {
+		spec     string
+		uid, gid uint32
+	}{
+		{"root", 0, 0},
+		{"0", 0, 0},
+		{"4321", 4321, 0},
+		{"4321:99", 4321, 99},
+		{"root:1", 0, 1},
+	}
+	for _, tt := range tests {
+		cred, err := execCredential(tt.spec)
+		if err != nil {
+			t.Errorf("execCredential(%q): %v", tt.spec, err)
+			continue
+		}
+		if cred.Uid != tt.uid || cred.Gid != tt.gid {
+			t.Errorf("execCredential(%q) = %d:%d, want %d:%d", tt.spec, cred.Uid, cred.Gid, tt.uid, tt.gid)
+		}
+	}
+	if _, err := execCredential("root:no-such-group"); err == nil {
+		t.Errorf("execCredential succeeded with an unknown group")
+	}
+}
+
+// Stopping a task stops the processes it started as well.
+func TestExecStopSignalsProcessGroup(t *testing.T) {
+	e, id := runExec(t, Config{Name: "sh", Cmd: []string{"/bin/sh", "-c", "sleep 60 & echo $!; wait"}})
+	p, _ := e.process(id)
+
+	var child int
+	deadline := time.Now().Add(5 * time.Second)
+	for child == 0 && time.Now().Before(deadline) {
+		out, _ := os.ReadFile(p.StdoutPath)
+		if s := strings.TrimSpace(string(out)); s != "" {
+			child, _ = strconv.Atoi(s)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if child == 0 {
+		t.Fatal("the task did not start its child")
+	}
+
+	if res := e.Stop(id, StopOptions{Timeout: 5}); res.Error != nil {
+		t.Fatalf("Stop: %v", res.Error)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for running(child) {
+		if time.Now().After(deadline) {
+			syscall.Kill(child, syscall.SIGKILL)
+			t.Fatal("the child of the task is still running after the task was stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// running reports whether a process exists and has not exited, as a child
+// that is not reaped yet still exists.
+func running(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestExecStopKillsProcessIgnoringSignal(t *testing.T) {
+	e, id := runExec(t, Config{Name: "stubborn", Cmd: []string{"/bin/sh", "-c", `trap "" TERM; echo ready; while :; do sleep 0.1; done`}})
+	p, _ := e.process(id)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, _ := os.ReadFile(p.StdoutPath)
+		if string(out) == "ready\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the task did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	start := time.Now()
+	res := e.Stop(id, StopOptions{Timeout: 1})
+	if res.Error != nil {
+		t.Fatalf("Stop: %v", res.Error)
+	}
+	if res.Result != StopKilled {
+		t.Errorf("stop result = %q, want %q", res.Result, StopKilled)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("process was killed after %v, before the timeout of 1s", waited)
+	}
+	if p.ExitCode != 128+int(syscall.SIGKILL) {
+		t.Errorf("exit code = %d, want %d", p.ExitCode, 128+int(syscall.SIGKILL))
+	}
+}
+
+func TestExecInspectExitCode(t *testing.T) {
+	e, id := runExec(t, Config{Name: "fail", Cmd: []string{"/bin/sh", "-c", "exit 3"}})
+	p, _ := e.process(id)
+	<-p.done
+
+	resp := e.Inspect(id)
+	if resp.Error != nil {
+		t.Fatalf("Inspect: %v", resp.Error)
+	}
+	s := resp.Container.State
+	if s.Running || s.Status != "exited" || s.ExitCode != 3 {
+		t.Errorf("state %q, running %v, exit code %d, want exited with code 3", s.Status, s.Running, s.ExitCode)
+	}
+	if s.FinishedAt == "" {
+		t.Errorf("finished process has no FinishedAt")
+	}
+}
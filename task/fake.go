@@ -0,0 +1,309 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+)
+
+// FakeBehaviour describes how a fake container started from a given image
+// behaves. The zero value starts instantly and runs until it is stopped.
+type FakeBehaviour struct {
+	StartLatency time.Duration // how long Run blocks before the container is running
+	StartError   error         // if set, Run fails with this error
+	RunFor       time.Duration // if set, the container exits on its own after this long
+	ExitCode     int           // exit code reported once the container exits
+	OOMKilled    bool
+	IgnoreStop   bool   // if set, the stop signal is ignored and the container is killed
+	HealthStatus int    // status code served on every path of the exposed ports, 0 means no server
+	Output       string // returned by Logs on stdout
+}
+
+type FakeContainer struct {
+	ID         string
+	Config     Config
+	Behaviour  FakeBehaviour
+	Status     string
+	ExitCode   int
+	OOMKilled  bool
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Ports      nat.PortMap
+	servers    []*http.Server
+}
+
+// Fake is an in-process Runtime that simulates container lifecycles so a
+// worker can be exercised without a Docker daemon. Behaviours are looked up
+// by image name, falling back to Default.
+type Fake struct {
+	mu         sync.Mutex
+	Default    FakeBehaviour
+	Behaviours map[string]FakeBehaviour
+	Containers map[string]*FakeContainer
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		Behaviours: make(map[string]FakeBehaviour),
+		Containers: make(map[string]*FakeContainer),
+	}
+}
+
+// SetBehaviour registers the behaviour used for containers started from image.
+func (f *Fake) SetBehaviour(image string, b FakeBehaviour) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Behaviours[image] = b
+}
+
+func (f *Fake) behaviour(image string) FakeBehaviour {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.Behaviours[image]
+	if !ok {
+		return f.Default
+	}
+	return b
+}
+
+func (f *Fake) Run(c Config) DockerResult {
+	b := f.behaviour(c.Image)
+	time.Sleep(b.StartLatency)
+	if b.StartError != nil {
+		log.Printf("Error starting fake container using image %s: %v\n", c.Image, b.StartError)
+		return DockerResult{Error: b.StartError}
+	}
+
+	fc := &FakeContainer{
+		ID:        uuid.New().String(),
+		Config:    c,
+		Behaviour: b,
+		Status:    "running",
+		StartedAt: time.Now().UTC(),
+		Ports:     nat.PortMap{},
+	}
+
+	for p := range c.ExposedPorts {
+		hostPort := "0"
+		if b := c.PortBindings[p]; len(b) > 0 && b[0].HostPort != "" {
+			hostPort = b[0].HostPort
+		}
+		hostPort, err := fc.serve(p.Proto(), hostPort)
+		if err != nil {
+			fc.exit(0, false)
+			log.Printf("Error assigning port for fake container using image %s: %v\n", c.Image, err)
+			return DockerResult{Error: err}
+		}
+		fc.Ports[p] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: hostPort}}
+	}
+
+	f.mu.Lock()
+	f.Containers[fc.ID] = fc
+	f.mu.Unlock()
+
+	return DockerResult{ContainerId: fc.ID, Action: "start", Result: "success"}
+}
+
+// serve listens on a loopback port ("0" picks a random one) for one exposed
+// port and, when the behaviour asks for it, answers every request to a tcp
+// port with HealthStatus so health checks can be pointed at the fake
+// container.
+func (fc *FakeContainer) serve(proto string, hostPort string) (string, error) {
+	addr := net.JoinHostPort("127.0.0.1", hostPort)
+	if proto == "udp" {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return "", err
+		}
+		defer pc.Close()
+		_, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+		return port, nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	if fc.Behaviour.HealthStatus == 0 {
+		l.Close()
+		return port, nil
+	}
+
+	status := fc.Behaviour.HealthStatus
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})}
+	fc.servers = append(fc.servers, srv)
+	go srv.Serve(l)
+	return port, nil
+}
+
+func (fc *FakeContainer) exit(code int, oomKilled bool) {
+	fc.Status = "exited"
+	fc.ExitCode = code
+	fc.OOMKilled = oomKilled
+	fc.FinishedAt = time.Now().UTC()
+	for _, srv := range fc.servers {
+		srv.Close()
+	}
+	fc.servers = nil
+}
+
+// refresh moves a running container to exited once its RunFor has elapsed.
+func (fc *FakeContainer) refresh() {
+	if fc.Status != "running" || fc.Behaviour.RunFor == 0 {
+		return
+	}
+	if time.Since(fc.StartedAt) >= fc.Behaviour.RunFor {
+		fc.exit(fc.Behaviour.ExitCode, fc.Behaviour.OOMKilled)
+	}
+}
+
+// Crash makes a running container exit immediately with the given code.
+func (f *Fake) Crash(id string, exitCode int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fc, ok := f.Containers[id]
+	if !ok {
+		return fmt.Errorf("no such container: %s", id)
+	}
+	fc.exit(exitCode, false)
+	return nil
+}
+
+func (f *Fake) Stop(id string, opts StopOptions) DockerResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fc, ok := f.Containers[id]
+	if !ok {
+		err := fmt.Errorf("no such container: %s", id)
+		log.Printf("Error stopping container %s: %v\n", id, err)
+		return DockerResult{Error: err}
+	}
+	stopResult := StopGraceful
+	if fc.Status == "running" {
+		if fc.Behaviour.IgnoreStop {
+			fc.exit(137, false)
+			stopResult = StopKilled
+		} else {
+			fc.exit(0, false)
+		}
+	}
+	delete(f.Containers, id)
+	return DockerResult{Action: "stop", Result: stopResult, Error: nil}
+}
+
+func (f *Fake) Inspect(id string) DockerInspectResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fc, ok := f.Containers[id]
+	if !ok {
+		err := fmt.Errorf("no such container: %s", id)
+		log.Printf("Error inspecting container %s\n", err)
+		return DockerInspectResponse{Error: err}
+	}
+	fc.refresh()
+
+	var finishedAt string
+	if !fc.FinishedAt.IsZero() {
+		finishedAt = fc.FinishedAt.Format(time.RFC3339Nano)
+	}
+
+	resp := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    fc.ID,
+			Name:  "/" + fc.Config.Name,
+			Image: fc.Config.Image,
+			State: &types.ContainerState{
+				Status:     fc.Status,
+				Running:    fc.Status == "running",
+				ExitCode:   fc.ExitCode,
+				OOMKilled:  fc.OOMKilled,
+				StartedAt:  fc.StartedAt.Format(time.RFC3339Nano),
+				FinishedAt: finishedAt,
+			},
+		},
+		Config: &container.Config{
+			Image:        fc.Config.Image,
+			Cmd:          fc.Config.Cmd,
+			Env:          fc.Config.Env,
+			ExposedPorts: fc.Config.ExposedPorts,
+		},
+		NetworkSettings: &types.NetworkSettings{
+			NetworkSettingsBase: types.NetworkSettingsBase{Ports: fc.Ports},
+		},
+	}
+	return DockerInspectResponse{Container: &resp}
+}
+
+func (f *Fake) Logs(ctx context.Context, id string, opts LogOptions, stdout, stderr io.Writer) error {
+	f.mu.Lock()
+	fc, ok := f.Containers[id]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no such container: %s", id)
+	}
+	if opts.Stdout {
+		_, err := io.WriteString(stdout, fc.Behaviour.Output)
+		return err
+	}
+	return nil
+}
+
+// Exec echoes the command back as its output and exits with 0, which is
+// enough to exercise the exec plumbing between CLI, manager and worker.
+func (f *Fake) Exec(ctx context.Context, id string, opts ExecOptions) (*ExecSession, error) {
+	f.mu.Lock()
+	fc, ok := f.Containers[id]
+	running := ok && fc.Status == "running"
+	f.mu.Unlock()
+	if !running {
+		return nil, fmt.Errorf("no running container: %s", id)
+	}
+
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		var out io.Writer = server
+		if !opts.Tty {
+			out = stdcopy.NewStdWriter(server, stdcopy.Stdout)
+		}
+		fmt.Fprintln(out, strings.Join(opts.Cmd, " "))
+	}()
+	return &ExecSession{ID: uuid.New().String(), Conn: client, Reader: bufio.NewReader(client)}, nil
+}
+
+func (f *Fake) ExecInspect(execID string) (ExecResult, error) {
+	return ExecResult{ID: execID}, nil
+}
+
+func (f *Fake) Stats(id string) DockerStatsResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fc, ok := f.Containers[id]
+	if !ok {
+		return DockerStatsResponse{Error: fmt.Errorf("no such container: %s", id)}
+	}
+	s := types.StatsJSON{
+		Name: "/" + fc.Config.Name,
+		ID:   fc.ID,
+	}
+	s.Read = time.Now().UTC()
+	s.MemoryStats.Limit = uint64(fc.Config.Memory)
+	return DockerStatsResponse{Stats: &s}
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+)
+
+func TestFakeStats(t *testing.T) {
+	var rt Runtime = NewFake()
+	res := rt.Run(Config{Name: "web", Image: "web", Memory: 64 << 20})
+	if res.Error != nil {
+		t.Fatalf("Run: %v", res.Error)
+	}
+
+	resp := rt.Stats(res.ContainerId)
+	if resp.Error != nil {
+		t.Fatalf("Stats: %v", resp.Error)
+	}
+	if resp.Stats.ID != res.ContainerId || resp.Stats.Name != "/web" {
+		t.Errorf("stats of %s %q, want %s %q", resp.Stats.ID, resp.Stats.Name, res.ContainerId, "/web")
+	}
+	if resp.Stats.MemoryStats.Limit != 64<<20 {
+		t.Errorf("memory limit = %d, want %d", resp.Stats.MemoryStats.Limit, 64<<20)
+	}
+
+	if resp := rt.Stats("no-such-container"); resp.Error == nil {
+		t.Errorf("Stats of an unknown container succeeded")
+	}
+}
+
+// freePort returns a loopback tcp port nothing listens on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func TestFakePortBindings(t *testing.T) {
+	f := NewFake()
+	f.SetBehaviour("web", FakeBehaviour{HealthStatus: http.StatusOK})
+	web, tls := freePort(t), freePort(t)
+	ports, bindings := NewPortConfig(nat.PortSet{"8000/tcp": {}}, map[string]string{
+		"80/tcp":  web,
+		"443/tcp": tls,
+		"53/udp":  web,
+	})
+	res := f.Run(Config{Name: "web", Image: "web", ExposedPorts: ports, PortBindings: bindings})
+	if res.Error != nil {
+		t.Fatalf("Run: %v", res.Error)
+	}
+	defer f.Stop(res.ContainerId, StopOptions{})
+
+	got := f.Inspect(res.ContainerId).Container.NetworkSettings.Ports
+	for p, want := range map[nat.Port]string{"80/tcp": web, "443/tcp": tls, "53/udp": web} {
+		if len(got[p]) != 1 || got[p][0].HostPort != want {
+			t.Errorf("port %s bound to %v, want host port %s", p, got[p], want)
+		}
+	}
+	unbound := got["8000/tcp"]
+	if len(unbound) != 1 || unbound[0].HostPort == web || unbound[0].HostPort == tls {
+		t.Errorf("port 8000/tcp bound to %v, want a port of its own", unbound)
+	}
+
+	for _, port := range []string{web, tls, unbound[0].HostPort} {
+		resp, err := http.Get("http://127.0.0.1:" + port)
+		if err != nil {
+			t.Errorf("nothing serves host port %s: %v", port, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,162 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+)
+
+const OwnerGroup = "group"
+
+// States of a task group.
+const (
+	GroupPending      = "Pending"      // waiting for a node
+	GroupInitializing = "Initializing" // running init tasks one at a time
+	GroupRunning      = "Running"      // all tasks have been started
+	GroupCompleted    = "Completed"    // a task exited cleanly or the group was stopped
+	GroupFailed       = "Failed"       // an init task or a task failed
+)
+
+// TaskGroup is a set of tasks that are always placed on the same node. The
+// InitTasks run one after the other and must each complete before the next
+// one starts; the first of the Tasks is then started, and the others once
+// it is running. Every task of the group joins the network namespace of the
+// first one in Tasks, which is why the ports of all tasks are published
+// through it and the others run at its priority, and Volumes are mounted
+// into every task. When one of the Tasks exits the others are stopped.
+type TaskGroup struct {
+	Name           string
+	InitTasks      []Task
+	Tasks          []Task
+	Volumes        []Volume
+	Node           string
+	State          string
+	Reason         string
+	InitTaskIDs    []uuid.UUID
+	TaskIDs        []uuid.UUID
+	CreatedAt      time.Time
+	CompletionTime time.Time
+}
+
+// Finished reports whether the group has completed or failed.
+func (g *TaskGroup) Finished() bool {
+	return g.State == GroupCompleted || g.State == GroupFailed
+}
+
+// Resources returns a task standing in for the whole group when picking a
+// node. Init tasks run before the other tasks, so the group needs the
+// larger of what the biggest init task and all tasks together ask for. The
+// node has to satisfy the selectors and affinities of every task, and may
+// only have taints all of them tolerate.
+func (g *TaskGroup) Resources() Task {
+	r := Task{Name: g.Name, PortBindings: make(map[string]string), ExposedPorts: nat.PortSet{}}
+	var initCpu float64
+	var initMemory int
+	for _, t := range g.InitTasks {
+		initCpu = max(initCpu, t.Cpu)
+		initMemory = max(initMemory, t.Memory)
+		r.Disk += t.DiskRequest()
+	}
+	for _, t := range slices.Concat(g.InitTasks, g.Tasks) {
+		for k, v := range t.NodeSelector {
+			if r.NodeSelector == nil {
+				r.NodeSelector = make(map[string]string)
+			}
+			r.NodeSelector[k] = v
+		}
+		if t.Affinity != nil {
+			if r.Affinity == nil {
+				r.Affinity = &Affinity{}
+			}
+			r.Affinity.NodeAffinity = r.Affinity.NodeAffinity.Merge(t.Affinity.NodeAffinity)
+			r.Affinity.TaskAffinity = r.Affinity.TaskAffinity.Merge(t.Affinity.TaskAffinity)
+			r.Affinity.TaskAntiAffinity = r.Affinity.TaskAntiAffinity.Merge(t.Affinity.TaskAntiAffinity)
+		}
+	}
+	r.Tolerations = commonTolerations(slices.Concat(g.InitTasks, g.Tasks))
+	for _, t := range g.Tasks {
+		r.Cpu += t.Cpu
+		r.Memory += t.Memory
+		r.Disk += t.DiskRequest()
+		for k, v := range t.PortBindings {
+			r.PortBindings[k] = v
+		}
+		for p := range t.ExposedPorts {
+			r.ExposedPorts[p] = struct{}{}
+		}
+	}
+	r.Cpu = max(r.Cpu, initCpu)
+	r.Memory = max(r.Memory, initMemory)
+	for _, v := range g.Volumes {
+		if v.Type != VolumeTypeTmpfs {
+			r.Disk += v.Size
+		}
+	}
+	if len(g.Tasks) > 0 {
+		r.Image = g.Tasks[0].Image
+	}
+	return r
+}
+
+// commonTolerations returns the tolerations every task has, as a taint is
+// only tolerated by the group if all of its tasks tolerate it.
+func commonTolerations(tasks []Task) []Toleration {
+	if len(tasks) == 0 {
+		return nil
+	}
+	var common []Toleration
+	for _, tol := range tasks[0].Tolerations {
+		if !slices.ContainsFunc(tasks[1:], func(t Task) bool { return !slices.Contains(t.Tolerations, tol) }) {
+			common = append(common, tol)
+		}
+	}
+	return common
+}
+
+func ValidateTaskGroup(g TaskGroup) error {
+	var errs []error
+	if g.Name == "" {
+		errs = append(errs, errors.New("Name is required"))
+	}
+	if len(g.Tasks) == 0 {
+		errs = append(errs, errors.New("at least one task is required"))
+	}
+	for i, t := range g.InitTasks {
+		if err := Validate(t); err != nil {
+			errs = append(errs, fmt.Errorf("invalid init task %d: %w", i, err))
+		}
+	}
+	hostPorts := make(map[string]bool)
+	for i, t := range g.Tasks {
+		if err := Validate(t); err != nil {
+			errs = append(errs, fmt.Errorf("invalid task %d: %w", i, err))
+		}
+		if t.NetworkMode != "" {
+			errs = append(errs, fmt.Errorf("task %d must not set NetworkMode, tasks of a group share one", i))
+		}
+		for _, p := range t.BoundHostPorts() {
+			if hostPorts[p] {
+				errs = append(errs, fmt.Errorf("host port %s is bound by more than one task", p))
+			}
+			hostPorts[p] = true
+		}
+	}
+	for _, v := range g.Volumes {
+		if err := validateVolume(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(g.Volumes) > 0 {
+		for _, t := range append(g.InitTasks, g.Tasks...) {
+			if t.Driver == "exec" {
+				errs = append(errs, errors.New("Volumes are not supported by the exec driver"))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+const (
+	PullAlways       = "Always"
+	PullIfNotPresent = "IfNotPresent"
+	PullNever        = "Never"
+)
+
+// ImagePullError is returned by Run when the image of a task could not be
+// made available, so the worker can report why the task failed.
+type ImagePullError struct {
+	Image string
+	Err   error
+}
+
+func (e *ImagePullError) Error() string {
+	return fmt.Sprintf("unable to pull image %s: %v", e.Image, e.Err)
+}
+
+func (e *ImagePullError) Unwrap() error {
+	return e.Err
+}
+
+// PullPolicy returns the effective pull policy for an image. Without an
+// explicit policy, images tagged latest (or untagged) are always pulled and
+// any other tag is only pulled when missing.
+func PullPolicy(policy string, img string) string {
+	if policy != "" {
+		return policy
+	}
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return PullAlways
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return PullIfNotPresent
+	}
+	if tagged, ok := named.(reference.Tagged); ok && tagged.Tag() != "latest" {
+		return PullIfNotPresent
+	}
+	return PullAlways
+}
+
+// RegistryHost returns the registry an image is pulled from, e.g.
+// "docker.io" or "ghcr.io".
+func RegistryHost(img string) string {
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return ""
+	}
+	return reference.Domain(named)
+}
+
+func (d *Docker) pullImage(ctx context.Context, c Config) error {
+	policy := PullPolicy(c.ImagePullPolicy, c.Image)
+	if policy != PullAlways {
+		_, _, err := d.Client.ImageInspectWithRaw(ctx, c.Image)
+		if err == nil {
+			log.Printf("Image %s is present, not pulling (policy %s)\n", c.Image, policy)
+			return nil
+		}
+		if !client.IsErrNotFound(err) {
+			return &ImagePullError{Image: c.Image, Err: err}
+		}
+		if policy == PullNever {
+			return &ImagePullError{Image: c.Image, Err: fmt.Errorf("image not present and pull policy is %s", PullNever)}
+		}
+	}
+
+	reader, err := d.Client.ImagePull(ctx, c.Image, image.PullOptions{RegistryAuth: c.RegistryAuth})
+	if err != nil {
+		return &ImagePullError{Image: c.Image, Err: err}
+	}
+	defer reader.Close()
+	io.Copy(os.Stdout, reader)
+	return nil
+}
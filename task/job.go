@@ -0,0 +1,67 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const OwnerJob = "job"
+
+// States of a job.
+const (
+	JobRunning   = "Running"
+	JobSucceeded = "Succeeded"
+	JobFailed    = "Failed"
+)
+
+// Reasons a job failed, recorded on Job.Reason.
+const (
+	ReasonBackoffLimitExceeded = "BackoffLimitExceeded"
+	ReasonDeadlineExceeded     = "DeadlineExceeded"
+)
+
+// Job asks the manager to run Template to completion Completions times,
+// with at most Parallelism tasks at once. A task succeeds when it exits with
+// code 0. Failed tasks are retried until more than BackoffLimit have failed,
+// and the job fails once it has been running for ActiveDeadline seconds.
+// Completions and Parallelism default to 1, a zero ActiveDeadline means no
+// deadline.
+type Job struct {
+	Name           string
+	Template       Task
+	Completions    int
+	Parallelism    int
+	BackoffLimit   int
+	ActiveDeadline int
+	State          string
+	Reason         string
+	Active         int
+	Succeeded      int
+	Failed         int
+	CreatedAt      time.Time
+	StartTime      time.Time
+	CompletionTime time.Time
+}
+
+// Finished reports whether the job has succeeded or failed.
+func (j *Job) Finished() bool {
+	return j.State == JobSucceeded || j.State == JobFailed
+}
+
+func ValidateJob(j Job) error {
+	var errs []error
+	if j.Name == "" {
+		errs = append(errs, errors.New("Name is required"))
+	}
+	if j.Completions < 0 || j.Parallelism < 0 || j.BackoffLimit < 0 || j.ActiveDeadline < 0 {
+		errs = append(errs, errors.New("Completions, Parallelism, BackoffLimit and ActiveDeadline must not be negative"))
+	}
+	if j.Template.EffectiveRestartPolicy() == RestartAlways {
+		errs = append(errs, fmt.Errorf("RestartPolicy %q never lets a task complete", j.Template.RestartPolicy))
+	}
+	if err := Validate(j.Template); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Template: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Probe is a check the worker runs periodically against a running task.
+// Exactly one of HTTPGet, TCPSocket or Exec is set. An HTTP probe passes on
+// a 2xx or 3xx status, a TCP probe when the port accepts a connection and
+// an exec probe when the command exits with 0. A probe changes its result
+// after SuccessThreshold consecutive successes or FailureThreshold
+// consecutive failures.
+type Probe struct {
+	HTTPGet          *HTTPGetHook
+	TCPSocket        *TCPSocketProbe
+	Exec             []string
+	InitialDelay     int // seconds after the task started before the first probe
+	Period           int // seconds between probes, defaults to 10
+	Timeout          int // seconds, defaults to 1
+	SuccessThreshold int // defaults to 1
+	FailureThreshold int // defaults to 3
+}
+
+type TCPSocketProbe struct {
+	Port string // container port, e.g. "5432/tcp"
+}
+
+// WithDefaults returns the probe with unset fields filled in.
+func (p Probe) WithDefaults() Probe {
+	if p.Period == 0 {
+		p.Period = 10
+	}
+	if p.Timeout == 0 {
+		p.Timeout = 1
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = 1
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = 3
+	}
+	return p
+}
+
+// Probes returns the liveness and readiness probes of the task. A task
+// that only sets the older HealthCheck path gets an HTTP probe against its
+// first published port for both, run every 60 seconds.
+func (t *Task) Probes() (liveness, readiness *Probe) {
+	liveness, readiness = t.LivenessProbe, t.ReadinessProbe
+	if t.HealthCheck == "" {
+		return liveness, readiness
+	}
+	legacy := &Probe{HTTPGet: &HTTPGetHook{Path: t.HealthCheck}, Period: 60}
+	if liveness == nil {
+		liveness = legacy
+	}
+	if readiness == nil {
+		readiness = legacy
+	}
+	return liveness, readiness
+}
+
+func validateProbe(name string, p *Probe) error {
+	set := 0
+	if p.HTTPGet != nil {
+		set++
+	}
+	if p.TCPSocket != nil {
+		set++
+		if p.TCPSocket.Port == "" {
+			return fmt.Errorf("%s TCPSocket requires a Port", name)
+		}
+	}
+	if len(p.Exec) > 0 {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("%s must set exactly one of HTTPGet, TCPSocket or Exec", name)
+	}
+	if p.InitialDelay < 0 || p.Period < 0 || p.Timeout < 0 || p.SuccessThreshold < 0 || p.FailureThreshold < 0 {
+		return errors.New(name + " timings and thresholds must not be negative")
+	}
+	return nil
+}
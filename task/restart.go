@@ -0,0 +1,141 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Restart policies of a task. The manager restarts a task that stopped
+// running on its own according to its policy; tasks stopped on request are
+// never restarted.
+const (
+	RestartNever     = "Never"     // the default
+	RestartOnFailure = "OnFailure" // restart when the task failed
+	RestartAlways    = "Always"    // restart whenever the task exited
+)
+
+// ReasonCrashLoopBackOff is recorded while a task waits out its backoff
+// before being restarted.
+const ReasonCrashLoopBackOff = "CrashLoopBackOff"
+
+// maxRestartHistory is how many restart times are kept on a task.
+const maxRestartHistory = 10
+
+// backoffResetAfter is how long a task has to run for its backoff to start
+// over from the initial delay.
+const backoffResetAfter = 10 * time.Minute
+
+// RestartBackoff controls how long the manager waits before restarting a
+// task. The delay doubles with every consecutive restart up to Max, plus a
+// jitter of up to a tenth of the delay.
+type RestartBackoff struct {
+	Initial         int // seconds before the first restart, defaults to 10
+	Max             int // seconds, defaults to 300
+	RescheduleAfter int // consecutive restarts on one node before moving to another, 0 never moves the task
+}
+
+// WithDefaults returns the backoff with unset fields filled in.
+func (b RestartBackoff) WithDefaults() RestartBackoff {
+	if b.Initial == 0 {
+		b.Initial = 10
+	}
+	if b.Max == 0 {
+		b.Max = 300
+	}
+	return b
+}
+
+// EffectiveRestartPolicy returns the restart policy of the task. The Docker
+// restart policies tasks used to pass through to their containers map to
+// the closest policy.
+func (t *Task) EffectiveRestartPolicy() string {
+	switch t.RestartPolicy {
+	case "always", "unless-stopped":
+		return RestartAlways
+	case "on-failure":
+		return RestartOnFailure
+	case "", "no":
+		return RestartNever
+	}
+	return t.RestartPolicy
+}
+
+// ShouldRestart reports whether the task has stopped running in a way its
+// restart policy asks to restart it for.
+func (t *Task) ShouldRestart() bool {
+	if t.StopRequested || t.Reason == ReasonStopped {
+		return false
+	}
+	switch t.EffectiveRestartPolicy() {
+	case RestartAlways:
+		return t.State == Completed || t.State == Failed
+	case RestartOnFailure:
+		return t.State == Failed
+	}
+	return false
+}
+
+// BackOff records that the task stopped running and will be restarted, and
+// returns how long to wait before doing so.
+func (t *Task) BackOff(now time.Time) time.Duration {
+	if t.FinishTime.Sub(t.StartTime) >= backoffResetAfter {
+		t.ConsecutiveRestarts = 0
+	}
+	t.ConsecutiveRestarts++
+
+	b := RestartBackoff{}
+	if t.RestartBackoff != nil {
+		b = *t.RestartBackoff
+	}
+	b = b.WithDefaults()
+	delay := time.Duration(b.Initial) * time.Second
+	max := time.Duration(b.Max) * time.Second
+	for i := 1; i < t.ConsecutiveRestarts && delay < max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max)
+	delay += time.Duration(rand.Int63n(int64(delay)/10 + 1))
+
+	t.State = Pending
+	t.Reason = ReasonCrashLoopBackOff
+	t.Ready = false
+	t.NextRestart = now.Add(delay)
+	return delay
+}
+
+// RescheduleDue reports whether the task has been restarted on its node
+// often enough in a row to be moved to another one.
+func (t *Task) RescheduleDue() bool {
+	return t.RestartBackoff != nil && t.RestartBackoff.RescheduleAfter > 0 &&
+		t.ConsecutiveRestarts > t.RestartBackoff.RescheduleAfter
+}
+
+// RecordRestart counts a restart of the task and adds it to the history.
+func (t *Task) RecordRestart(now time.Time) {
+	t.RestartCount++
+	t.RestartHistory = append(t.RestartHistory, now)
+	if len(t.RestartHistory) > maxRestartHistory {
+		t.RestartHistory = t.RestartHistory[len(t.RestartHistory)-maxRestartHistory:]
+	}
+}
+
+func validateRestart(t Task) error {
+	var errs []error
+	switch t.EffectiveRestartPolicy() {
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		errs = append(errs, fmt.Errorf("unknown RestartPolicy %q, expected %s, %s or %s",
+			t.RestartPolicy, RestartNever, RestartOnFailure, RestartAlways))
+	}
+	if b := t.RestartBackoff; b != nil {
+		if b.Initial < 0 || b.Max < 0 || b.RescheduleAfter < 0 {
+			errs = append(errs, errors.New("RestartBackoff values must not be negative"))
+		}
+		if b.Initial > 0 && b.Max > 0 && b.Max < b.Initial {
+			errs = append(errs, errors.New("RestartBackoff Max must not be less than Initial"))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Runtime is implemented by every backend a worker can use to run tasks.
+// Docker is the default implementation; other drivers only need to
+// satisfy this interface to be plugged into worker.New.
+type Runtime interface {
+	Run(c Config) DockerResult
+	Stop(id string, opts StopOptions) DockerResult
+	Inspect(id string) DockerInspectResponse
+	Logs(ctx context.Context, id string, opts LogOptions, stdout, stderr io.Writer) error
+	Stats(id string) DockerStatsResponse
+}
+
+type LogOptions struct {
+	Follow bool
+	Tail   string
+	Since  string
+	Stdout bool
+	Stderr bool
+}
+
+type DockerStatsResponse struct {
+	Error error
+	Stats *types.StatsJSON
+}
+
+type ExecOptions struct {
+	Cmd    []string
+	Tty    bool
+	Stdin  bool
+	Height uint
+	Width  uint
+}
+
+// ExecSession is a command started inside a running task. Conn carries the
+// command's input, Reader its output, multiplexed with stdcopy unless the
+// session was started with a TTY.
+type ExecSession struct {
+	ID     string
+	Conn   net.Conn
+	Reader *bufio.Reader
+}
+
+type ExecResult struct {
+	ID       string
+	Running  bool
+	ExitCode int
+}
+
+// Execer is implemented by runtimes that can run additional commands inside
+// an already running task.
+type Execer interface {
+	Exec(ctx context.Context, id string, opts ExecOptions) (*ExecSession, error)
+	ExecInspect(execID string) (ExecResult, error)
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const OwnerService = "service"
+
+const (
+	RolloutProgressing = "Progressing"
+	RolloutPaused      = "Paused"
+	RolloutComplete    = "Complete"
+)
+
+// Owner identifies the manager resource a task was created for. The zero
+// value means the task was submitted directly by a user.
+type Owner struct {
+	Kind string
+	Name string
+}
+
+func (o Owner) String() string {
+	if o.Kind == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", o.Kind, o.Name)
+}
+
+// Service asks the manager to keep Replicas copies of Template running.
+// Tasks submitted directly whose labels match Selector are adopted by the
+// service and replaced by the next rollout.
+type Service struct {
+	Name            string
+	Replicas        int
+	Selector        map[string]string
+	Template        Task
+	UpdateStrategy  UpdateStrategy
+	Revision        int
+	Rollout         RolloutStatus
+	RunningReplicas int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// UpdateStrategy bounds a rolling update: at most MaxSurge tasks above
+// Replicas may exist, and at most MaxUnavailable below Replicas may be
+// unavailable. When both are zero a surge of one is used.
+type UpdateStrategy struct {
+	MaxSurge       int
+	MaxUnavailable int
+}
+
+func (u UpdateStrategy) Limits() (surge, unavailable int) {
+	if u.MaxSurge == 0 && u.MaxUnavailable == 0 {
+		return 1, 0
+	}
+	return u.MaxSurge, u.MaxUnavailable
+}
+
+// RolloutStatus tracks the replacement of tasks running an older revision
+// of the template.
+type RolloutStatus struct {
+	Revision        int
+	State           string
+	Message         string
+	UpdatedReplicas int
+	OldReplicas     int
+	StartedAt       time.Time
+}
+
+// ServiceRevision is a template a service has run, kept so that a rollout
+// can be undone.
+type ServiceRevision struct {
+	Service   string
+	Revision  int
+	Template  Task
+	CreatedAt time.Time
+}
+
+// Selects reports whether t belongs to the service, either because the
+// service created it or because it was submitted with matching labels.
+func (s *Service) Selects(t *Task) bool {
+	if t.Owner == (Owner{Kind: OwnerService, Name: s.Name}) {
+		return true
+	}
+	if t.Owner.Kind != "" || len(s.Selector) == 0 {
+		return false
+	}
+	for k, v := range s.Selector {
+		if t.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Active reports whether a task counts towards a desired replica count:
+// it has not finished and nobody asked for it to be stopped.
+func (t *Task) Active() bool {
+	if t.StopRequested {
+		return false
+	}
+	return t.State == Pending || t.State == Scheduled || t.State == Running
+}
+
+func ValidateService(s Service) error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, errors.New("Name is required"))
+	}
+	if s.Replicas < 0 {
+		errs = append(errs, errors.New("Replicas must not be negative"))
+	}
+	if s.UpdateStrategy.MaxSurge < 0 || s.UpdateStrategy.MaxUnavailable < 0 {
+		errs = append(errs, errors.New("MaxSurge and MaxUnavailable must not be negative"))
+	}
+	if err := Validate(s.Template); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Template: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -23,7 +23,7 @@ func (s State) String() []string {
 
 var StateTransitionMap = map[State][]State{
 	Pending:   {Scheduled},
-	Scheduled: {Scheduled, Running, Failed},
+	Scheduled: {Scheduled, Running, Completed, Failed},
 	Running:   {Running, Completed, Failed},
 	Completed: {},
 	Failed:    {},
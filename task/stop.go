@@ -0,0 +1,76 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Outcomes of stopping a task, recorded on Task.StopResult and returned as
+// the Result of a stop by every Runtime.
+const (
+	StopGraceful = "Graceful" // the task exited after StopSignal
+	StopKilled   = "Killed"   // the task outlived StopTimeout and was killed
+)
+
+// StopOptions controls how a runtime stops a task. An empty Signal means
+// SIGTERM and a zero Timeout uses the runtime's default grace period.
+type StopOptions struct {
+	Signal  string
+	Timeout int // seconds
+}
+
+// Hook is an action run against a task before it is stopped, either an
+// HTTP GET to one of its ports or a command executed inside it.
+type Hook struct {
+	HTTPGet *HTTPGetHook
+	Exec    []string
+	Timeout int // seconds, defaults to DefaultHookTimeout
+}
+
+type HTTPGetHook struct {
+	Path string
+	Port string // container port, e.g. "8080/tcp"
+}
+
+const DefaultHookTimeout = 30
+
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+}
+
+// ParseSignal accepts a signal by name, with or without the SIG prefix, or
+// by number. An empty name is SIGTERM.
+func ParseSignal(name string) (syscall.Signal, error) {
+	if name == "" {
+		return syscall.SIGTERM, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+	s, ok := signals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+	return s, nil
+}
+
+func validateHook(h *Hook) error {
+	if (h.HTTPGet == nil) == (len(h.Exec) == 0) {
+		return fmt.Errorf("PreStop must set exactly one of HTTPGet or Exec")
+	}
+	if h.HTTPGet != nil && h.HTTPGet.Port == "" {
+		return fmt.Errorf("PreStop HTTPGet requires a Port")
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("PreStop Timeout must not be negative")
+	}
+	return nil
+}
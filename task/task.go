@@ -8,21 +8,56 @@ import (
 )
 
 type Task struct {
-	ID            uuid.UUID
-	Name          string
-	State         State
-	Image         string
-	Memory        int
-	Disk          int
-	ExposedPorts  nat.PortSet
-	PortBindings  map[string]string
-	RestartPolicy string
-	StartTime     time.Time
-	FinishTime    time.Time
-	ContainerId   string
-	HostPorts     nat.PortMap
-	HealthCheck   string
-	RestartCount  int
+	ID                  uuid.UUID
+	Name                string
+	State               State
+	Image               string
+	ImagePullPolicy     string
+	ImagePullSecret     string
+	Driver              string
+	Cmd                 []string
+	Entrypoint          []string
+	Env                 []string
+	WorkingDir          string
+	User                string
+	Labels              map[string]string
+	Cpu                 float64
+	Memory              int
+	Disk                int
+	Volumes             []Volume
+	ExposedPorts        nat.PortSet
+	PortBindings        map[string]string
+	NetworkMode         string
+	RestartPolicy       string // Never, OnFailure or Always
+	RestartBackoff      *RestartBackoff
+	StopSignal          string
+	StopTimeout         int
+	PreStop             *Hook
+	StartTime           time.Time
+	FinishTime          time.Time
+	ContainerId         string
+	HostPorts           nat.PortMap
+	HealthCheck         string
+	LivenessProbe       *Probe
+	ReadinessProbe      *Probe
+	Ready               bool
+	RestartCount        int
+	RestartHistory      []time.Time // when the task was last restarted, oldest first
+	ConsecutiveRestarts int
+	NextRestart         time.Time         // when a task in CrashLoopBackOff is restarted
+	Node                string            // worker the task has to run on, empty to let the scheduler pick one
+	NodeSelector        map[string]string // labels a node needs to have for the task to be placed on it
+	Affinity            *Affinity
+	Tolerations         []Toleration
+	Priority            int // higher priority tasks are scheduled first and may preempt lower priority ones
+	Owner               Owner
+	Revision            int
+	StopRequested       bool
+	ExitCode            int
+	OOMKilled           bool
+	Error               string
+	Reason              string
+	StopResult          string
 }
 
 type TaskEvent struct {
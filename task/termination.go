@@ -0,0 +1,61 @@
+package task
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Reasons a task stopped running, recorded on Task.Reason.
+const (
+	ReasonCompleted = "Completed" // exited on its own with code 0
+	ReasonFailed    = "Failed"    // exited with a non-zero code or could not be started
+	ReasonKilled    = "Killed"    // terminated by a signal, including the OOM killer
+	ReasonStopped   = "Stopped"   // stopped on request of a user or the manager
+
+	ReasonLivenessProbeFailed = "LivenessProbeFailed" // stopped by the worker as its liveness probe failed
+
+	ReasonImagePullFailed = "ImagePullFailed" // the image could not be pulled
+	ReasonNodeLost        = "NodeLost"        // the node running the task could not be reached
+	ReasonNodeDrained     = "NodeDrained"     // moved off a node that was drained
+	ReasonEvicted         = "Evicted"         // moved off a node with a NoExecute taint it does not tolerate
+	ReasonPreempted       = "Preempted"       // stopped to make room for a task of higher priority
+)
+
+// ReasonUnschedulable is recorded on a pending task that fits on no node.
+const ReasonUnschedulable = "Unschedulable"
+
+// TerminationReason classifies how a container exited. Exit codes above 128
+// are how runtimes report death by signal (128 + signal number).
+func TerminationReason(exitCode int, oomKilled bool) string {
+	switch {
+	case oomKilled || exitCode > 128:
+		return ReasonKilled
+	case exitCode == 0:
+		return ReasonCompleted
+	default:
+		return ReasonFailed
+	}
+}
+
+// Terminated records the final state of the task's container and moves the
+// task to Completed for a clean exit, or Failed otherwise.
+func (t *Task) Terminated(s *types.ContainerState) {
+	t.ExitCode = s.ExitCode
+	t.OOMKilled = s.OOMKilled
+	t.Error = s.Error
+	t.Reason = TerminationReason(s.ExitCode, s.OOMKilled)
+	t.Ready = false
+
+	finishedAt, err := time.Parse(time.RFC3339Nano, s.FinishedAt)
+	if err != nil || finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	t.FinishTime = finishedAt.UTC()
+
+	if t.Reason == ReasonCompleted {
+		t.State = Completed
+	} else {
+		t.State = Failed
+	}
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Operators of a toleration.
+const (
+	TolerationEqual  = "Equal"  // the taint has the Key and Value of the toleration, the default
+	TolerationExists = "Exists" // the taint has the Key of the toleration, or any key if it is empty
+)
+
+// Toleration lets a task be placed on, and keep running on, a node with a
+// matching taint. An empty Effect matches taints of every effect.
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// Tolerates reports whether the toleration matches a taint.
+func (tol Toleration) Tolerates(key, value, effect string) bool {
+	if tol.Effect != "" && tol.Effect != effect {
+		return false
+	}
+	if tol.Operator == TolerationExists {
+		return tol.Key == "" || tol.Key == key
+	}
+	return tol.Key == key && tol.Value == value
+}
+
+// Tolerates reports whether one of the tolerations of the task matches a
+// taint.
+func (t *Task) Tolerates(key, value, effect string) bool {
+	for _, tol := range t.Tolerations {
+		if tol.Tolerates(key, value, effect) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateToleration(tol Toleration) error {
+	switch tol.Operator {
+	case "", TolerationEqual:
+		if tol.Key == "" {
+			return errors.New("Key is required unless Operator is Exists")
+		}
+	case TolerationExists:
+		if tol.Value != "" {
+			return errors.New("Exists takes no Value")
+		}
+	default:
+		return fmt.Errorf("unknown Operator %q, expected %s or %s", tol.Operator, TolerationEqual, TolerationExists)
+	}
+	switch tol.Effect {
+	case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+	default:
+		return fmt.Errorf("unknown Effect %q, expected NoSchedule, PreferNoSchedule or NoExecute", tol.Effect)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+)
+
+var Drivers = []string{"docker", "exec", "fake"}
+
+// Validate checks a task spec submitted by a user before it is queued, so
+// malformed specs are rejected at the API instead of failing on a worker.
+func Validate(t Task) error {
+	var errs []error
+
+	if t.Driver != "" && !containsString(Drivers, t.Driver) {
+		errs = append(errs, fmt.Errorf("unknown driver %q", t.Driver))
+	}
+	if t.Driver == "exec" {
+		if len(t.Cmd) == 0 && len(t.Entrypoint) == 0 {
+			errs = append(errs, errors.New("Cmd or Entrypoint is required for the exec driver"))
+		}
+	} else if t.Image == "" {
+		errs = append(errs, errors.New("Image is required"))
+	}
+
+	switch t.ImagePullPolicy {
+	case "", PullAlways, PullIfNotPresent, PullNever:
+	default:
+		errs = append(errs, fmt.Errorf("unknown ImagePullPolicy %q, expected %s, %s or %s",
+			t.ImagePullPolicy, PullAlways, PullIfNotPresent, PullNever))
+	}
+
+	if t.Cpu < 0 {
+		errs = append(errs, errors.New("Cpu must not be negative"))
+	}
+	if t.Memory < 0 {
+		errs = append(errs, errors.New("Memory must not be negative"))
+	}
+	if t.Disk < 0 {
+		errs = append(errs, errors.New("Disk must not be negative"))
+	}
+
+	for _, e := range t.Env {
+		k, _, ok := strings.Cut(e, "=")
+		if !ok || k == "" {
+			errs = append(errs, fmt.Errorf("invalid Env entry %q, expected KEY=VALUE", e))
+		}
+	}
+
+	for k := range t.Labels {
+		if k == "" {
+			errs = append(errs, errors.New("label keys must not be empty"))
+		}
+	}
+
+	for p := range t.ExposedPorts {
+		if p.Int() == 0 {
+			errs = append(errs, fmt.Errorf("invalid exposed port %q", p))
+		}
+	}
+
+	if _, err := ParseSignal(t.StopSignal); err != nil {
+		errs = append(errs, err)
+	}
+	if t.StopTimeout < 0 {
+		errs = append(errs, errors.New("StopTimeout must not be negative"))
+	}
+	if t.PreStop != nil {
+		if err := validateHook(t.PreStop); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if t.LivenessProbe != nil {
+		if err := validateProbe("LivenessProbe", t.LivenessProbe); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if t.ReadinessProbe != nil {
+		if err := validateProbe("ReadinessProbe", t.ReadinessProbe); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateRestart(t); err != nil {
+		errs = append(errs, err)
+	}
+	for k := range t.NodeSelector {
+		if k == "" {
+			errs = append(errs, errors.New("NodeSelector keys must not be empty"))
+		}
+	}
+	if t.Affinity != nil {
+		if err := validateAffinity(t.Affinity); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, tol := range t.Tolerations {
+		if err := validateToleration(tol); err != nil {
+			errs = append(errs, fmt.Errorf("invalid toleration: %w", err))
+		}
+	}
+
+	targets := make(map[string]bool)
+	for _, v := range t.Volumes {
+		if err := validateVolume(v); err != nil {
+			errs = append(errs, err)
+		}
+		if targets[v.Target] {
+			errs = append(errs, fmt.Errorf("more than one volume mounted at %s", v.Target))
+		}
+		targets[v.Target] = true
+	}
+	if t.Driver == "exec" && len(t.Volumes) > 0 {
+		errs = append(errs, errors.New("Volumes are not supported by the exec driver"))
+	}
+
+	switch {
+	case t.NetworkMode == "", t.NetworkMode == "bridge", t.NetworkMode == "host", t.NetworkMode == "none":
+	case strings.HasPrefix(t.NetworkMode, "container:") && len(t.NetworkMode) > len("container:"):
+		if len(t.ExposedPorts) > 0 || len(t.PortBindings) > 0 {
+			errs = append(errs, errors.New("ports cannot be published when joining the network of another container"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown NetworkMode %q, expected bridge, host, none or container:<name>", t.NetworkMode))
+	}
+
+	// An empty or zero host port lets the runtime pick a free one, so like
+	// BoundHostPorts only other host ports are checked for clashes, which
+	// only happen on the same protocol.
+	hostPorts := make(map[string]string)
+	for k, hostPort := range t.PortBindings {
+		proto, port := nat.SplitProtoPort(k)
+		if _, err := nat.NewPort(proto, port); err != nil {
+			errs = append(errs, fmt.Errorf("invalid port binding %q: %v", k, err))
+		}
+		if hostPort == "" || hostPort == "0" {
+			continue
+		}
+		n, err := strconv.Atoi(hostPort)
+		if err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("invalid host port %q for %s", hostPort, k))
+			continue
+		}
+		bound := hostPort + "/" + proto
+		if other, ok := hostPorts[bound]; ok {
+			errs = append(errs, fmt.Errorf("host port %s is bound by both %s and %s", bound, other, k))
+		}
+		hostPorts[bound] = k
+	}
+
+	return errors.Join(errs...)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
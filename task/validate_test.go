@@ -0,0 +1,28 @@
+package task
+
+import "testing"
+
+func TestValidatePortBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[string]string
+		valid    bool
+	}{
+		{"one port", map[string]string{"80/tcp": "8080"}, true},
+		{"same host port on other protocols", map[string]string{"80/tcp": "8080", "80/udp": "8080"}, true},
+		{"same host port twice", map[string]string{"80/tcp": "8080", "81/tcp": "8080"}, false},
+		{"tcp is the default protocol", map[string]string{"80": "8080", "81/tcp": "8080"}, false},
+		{"host ports picked by the runtime", map[string]string{"80/tcp": "", "81/tcp": "0"}, true},
+		{"host port out of range", map[string]string{"80/tcp": "65536"}, false},
+		{"host port not a number", map[string]string{"80/tcp": "http"}, false},
+		{"invalid container port", map[string]string{"x/tcp": "8080"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(Task{Image: "web", PortBindings: tt.bindings})
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate(%v) = %v, want valid %v", tt.bindings, err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	VolumeTypeVolume = "volume"
+	VolumeTypeBind   = "bind"
+	VolumeTypeTmpfs  = "tmpfs"
+
+	VolumeRetain = "Retain"
+	VolumeDelete = "Delete"
+)
+
+// Volume is a mount requested by a task. Source is the volume name for
+// named volumes and the host path for bind mounts; it is unused for tmpfs.
+// Size is in bytes and is counted against the node's disk, except for tmpfs
+// where it caps the size of the in-memory filesystem.
+type Volume struct {
+	Type     string
+	Source   string
+	Target   string
+	ReadOnly bool
+	Size     int
+	Policy   string // Retain or Delete, named volumes only; defaults to Retain
+}
+
+// VolumeRemover is implemented by runtimes that manage named volumes, so
+// the worker can honour the Delete policy once a task has been stopped.
+type VolumeRemover interface {
+	RemoveVolume(name string) error
+}
+
+// DiskRequest is the disk a task needs on a node: its own Disk plus the
+// size of every volume that is backed by the node's disk.
+func (t *Task) DiskRequest() int {
+	disk := t.Disk
+	for _, v := range t.Volumes {
+		if v.Type != VolumeTypeTmpfs {
+			disk += v.Size
+		}
+	}
+	return disk
+}
+
+func validateVolume(v Volume) error {
+	switch v.Type {
+	case VolumeTypeVolume:
+		if v.Source == "" {
+			return fmt.Errorf("volume for %s requires a Source name", v.Target)
+		}
+	case VolumeTypeBind:
+		if !filepath.IsAbs(v.Source) {
+			return fmt.Errorf("bind mount source %q must be an absolute path", v.Source)
+		}
+	case VolumeTypeTmpfs:
+		if v.Source != "" {
+			return fmt.Errorf("tmpfs mount for %s must not have a Source", v.Target)
+		}
+	default:
+		return fmt.Errorf("unknown volume type %q", v.Type)
+	}
+
+	if !filepath.IsAbs(v.Target) {
+		return fmt.Errorf("volume target %q must be an absolute path", v.Target)
+	}
+	if v.Size < 0 {
+		return fmt.Errorf("volume size for %s must not be negative", v.Target)
+	}
+	if v.Policy != "" && v.Policy != VolumeRetain && v.Policy != VolumeDelete {
+		return fmt.Errorf("unknown volume policy %q, expected %s or %s", v.Policy, VolumeRetain, VolumeDelete)
+	}
+	return nil
+}
+
+// BindAllowed reports whether path lies under one of the allowed host
+// directories.
+func BindAllowed(path string, allowlist []string) bool {
+	path = filepath.Clean(path)
+	for _, dir := range allowlist {
+		dir = filepath.Clean(dir)
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const OwnerWorkflow = "workflow"
+
+// States of a workflow.
+const (
+	WorkflowRunning   = "Running"
+	WorkflowSucceeded = "Succeeded"
+	WorkflowFailed    = "Failed"
+)
+
+// States of a task within a workflow.
+const (
+	StepWaiting   = "Waiting"   // dependencies have not completed yet
+	StepStarted   = "Started"   // the task has been handed to the manager
+	StepSucceeded = "Succeeded" // the task completed with exit code 0
+	StepFailed    = "Failed"    // the task failed or was stopped
+	StepSkipped   = "Skipped"   // a dependency failed or was skipped
+)
+
+// WorkflowTask is a node of a workflow. Its task is only started once every
+// task named in DependsOn has succeeded.
+type WorkflowTask struct {
+	Name      string
+	DependsOn []string
+	Template  Task
+	TaskID    uuid.UUID
+	State     string
+	Reason    string
+}
+
+// Workflow is a graph of tasks run in dependency order. A failed task
+// causes every task depending on it, directly or not, to be skipped, while
+// independent branches run to the end.
+type Workflow struct {
+	Name           string
+	Tasks          []WorkflowTask
+	State          string
+	CreatedAt      time.Time
+	CompletionTime time.Time
+}
+
+// Finished reports whether the workflow has succeeded or failed.
+func (w *Workflow) Finished() bool {
+	return w.State == WorkflowSucceeded || w.State == WorkflowFailed
+}
+
+// Done reports whether the task has reached a final state.
+func (wt *WorkflowTask) Done() bool {
+	return wt.State == StepSucceeded || wt.State == StepFailed || wt.State == StepSkipped
+}
+
+func ValidateWorkflow(w Workflow) error {
+	var errs []error
+	if w.Name == "" {
+		errs = append(errs, errors.New("Name is required"))
+	}
+	if len(w.Tasks) == 0 {
+		errs = append(errs, errors.New("at least one task is required"))
+	}
+
+	deps := make(map[string][]string)
+	for _, wt := range w.Tasks {
+		if wt.Name == "" {
+			errs = append(errs, errors.New("every task needs a Name"))
+			continue
+		}
+		if _, ok := deps[wt.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate task name %s", wt.Name))
+		}
+		deps[wt.Name] = wt.DependsOn
+		if err := Validate(wt.Template); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Template of task %s: %w", wt.Name, err))
+		}
+	}
+	for _, wt := range w.Tasks {
+		for _, d := range wt.DependsOn {
+			if _, ok := deps[d]; !ok {
+				errs = append(errs, fmt.Errorf("task %s depends on unknown task %s", wt.Name, d))
+			}
+		}
+	}
+	if cycle := findCycle(deps); cycle != "" {
+		errs = append(errs, fmt.Errorf("dependency cycle through task %s", cycle))
+	}
+	return errors.Join(errs...)
+}
+
+// findCycle returns the name of a task on a dependency cycle, or an empty
+// string if the graph is acyclic.
+func findCycle(deps map[string][]string) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int)
+	var visit func(name string) string
+	visit = func(name string) string {
+		switch marks[name] {
+		case visiting:
+			return name
+		case visited:
+			return ""
+		}
+		marks[name] = visiting
+		for _, d := range deps[name] {
+			if _, ok := deps[d]; !ok {
+				continue
+			}
+			if c := visit(d); c != "" {
+				return c
+			}
+		}
+		marks[name] = visited
+		return ""
+	}
+	for name := range deps {
+		if c := visit(name); c != "" {
+			return c
+		}
+	}
+	return ""
+}
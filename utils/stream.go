@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"io"
+	"net"
+)
+
+// ProxyStream copies data between a client and a backend connection until
+// the backend has finished sending. The readers allow bytes already
+// buffered while reading HTTP headers to be forwarded. When the client
+// stops sending, the write side of the backend is closed so it sees EOF.
+// Both connections are closed on return.
+func ProxyStream(client net.Conn, clientR io.Reader, backend net.Conn, backendR io.Reader) {
+	done := make(chan struct{})
+	go func() {
+		io.Copy(backend, clientR)
+		closeWrite(backend)
+	}()
+	go func() {
+		io.Copy(client, backendR)
+		closeWrite(client)
+		close(done)
+	}()
+	<-done
+	backend.Close()
+	client.Close()
+}
+
+func closeWrite(c net.Conn) {
+	if cw, ok := c.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite()
+	}
+}
@@ -21,6 +21,9 @@ func (a *Api) initRouter() {
 		r.Get("/", a.GetTasksHandler)
 		r.Route("/{taskID}", func(r chi.Router) {
 			r.Delete("/", a.StopTaskHandler)
+			r.Get("/logs", a.GetTaskLogsHandler)
+			r.Post("/exec", a.ExecTaskHandler)
+			r.Get("/exec/{execID}", a.InspectExecHandler)
 		})
 	})
 	a.Router.Route("/stats", func(r chi.Router) {
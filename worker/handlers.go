@@ -1,14 +1,19 @@
 package worker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	"github.com/utsab818/my-orchestrator/task"
+	"github.com/utsab818/my-orchestrator/utils"
 )
 
 type ErrResponse struct {
@@ -84,3 +89,172 @@ func (a *Api) GetStatsHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(200)
 	json.NewEncoder(w).Encode(a.Worker.Stats)
 }
+
+// flushWriter flushes every write so followed logs reach the client as
+// they are produced. Stdout and stderr share it, hence the lock.
+type flushWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	n, err := fw.w.Write(p)
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+func (a *Api) GetTaskLogsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	tID, err := uuid.Parse(taskID)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid taskID %q: %v", taskID, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	opts, err := logOptions(r)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: err.Error()})
+		return
+	}
+
+	if _, err := a.Worker.Db.Get(tID.String()); err != nil {
+		log.Printf("No task with ID %v found", tID)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(200)
+	fw := &flushWriter{w: w}
+	err = a.Worker.TaskLogs(r.Context(), tID.String(), opts, fw, fw)
+	if err != nil {
+		log.Printf("Error streaming logs for task %v: %v\n", tID, err)
+	}
+}
+
+// logOptions reads follow, tail, since, stdout and stderr from the query
+// string. Both streams are returned unless one is explicitly disabled.
+func logOptions(r *http.Request) (task.LogOptions, error) {
+	q := r.URL.Query()
+	opts := task.LogOptions{
+		Tail:   q.Get("tail"),
+		Since:  q.Get("since"),
+		Stdout: true,
+		Stderr: true,
+	}
+
+	var err error
+	for name, dst := range map[string]*bool{"follow": &opts.Follow, "stdout": &opts.Stdout, "stderr": &opts.Stderr} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		*dst, err = strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid value %q for %s", v, name)
+		}
+	}
+
+	if opts.Tail != "" && opts.Tail != "all" {
+		if _, err := strconv.Atoi(opts.Tail); err != nil {
+			return opts, fmt.Errorf("invalid value %q for tail", opts.Tail)
+		}
+	}
+	return opts, nil
+}
+
+// ExecTaskHandler runs a command in the task's container. The connection is
+// hijacked and switched to a raw stream carrying the command's stdin and
+// output; the exec ID is returned in the X-Exec-Id header so its exit code
+// can be read afterwards.
+func (a *Api) ExecTaskHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	tID, err := uuid.Parse(taskID)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid taskID %q: %v", taskID, err)
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	opts := task.ExecOptions{}
+	err = json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || len(opts.Cmd) == 0 {
+		msg := fmt.Sprintf("Error unmarshalling body: %v", err)
+		if err == nil {
+			msg = "no command given"
+		}
+		log.Println(msg)
+		w.WriteHeader(400)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 400, Message: msg})
+		return
+	}
+
+	if _, err := a.Worker.Db.Get(tID.String()); err != nil {
+		log.Printf("No task with ID %v found", tID)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: err.Error()})
+		return
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(500)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 500, Message: "connection cannot be hijacked"})
+		return
+	}
+
+	session, err := a.Worker.ExecTask(context.Background(), tID.String(), opts)
+	if err != nil {
+		code := 500
+		if errors.Is(err, ErrExecNotSupported) {
+			code = 501
+		}
+		msg := fmt.Sprintf("Error running exec in task %v: %v", tID, err)
+		log.Println(msg)
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: code, Message: msg})
+		return
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		log.Printf("Error hijacking connection for exec %s: %v\n", session.ID, err)
+		session.Conn.Close()
+		return
+	}
+
+	fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: tcp\r\nX-Exec-Id: %s\r\n\r\n", session.ID)
+	log.Printf("Started exec %s in task %v\n", session.ID, tID)
+	utils.ProxyStream(conn, buf.Reader, session.Conn, session.Reader)
+	log.Printf("Exec %s in task %v finished\n", session.ID, tID)
+}
+
+func (a *Api) InspectExecHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "taskID")
+	execID := chi.URLParam(r, "execID")
+
+	result, err := a.Worker.InspectExec(taskID, execID)
+	if err != nil {
+		msg := fmt.Sprintf("Error inspecting exec %s: %v", execID, err)
+		log.Println(msg)
+		w.WriteHeader(404)
+		json.NewEncoder(w).Encode(ErrResponse{HTTPStatusCode: 404, Message: msg})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,266 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/utsab818/my-orchestrator/task"
+)
+
+// probeState tracks the consecutive results of one probe of one container.
+type probeState struct {
+	lastRun   time.Time
+	successes int
+	failures  int
+	passing   bool
+}
+
+// record adds the result of a probe run and reports whether the probe now
+// passes, which only changes once a threshold is reached.
+func (s *probeState) record(ok bool, p task.Probe) bool {
+	if ok {
+		s.successes++
+		s.failures = 0
+		if s.successes >= p.SuccessThreshold {
+			s.passing = true
+		}
+	} else {
+		s.failures++
+		s.successes = 0
+		if s.failures >= p.FailureThreshold {
+			s.passing = false
+		}
+	}
+	return s.passing
+}
+
+// RunProbes runs the liveness and readiness probes of the running tasks.
+// A task whose readiness probe fails is marked as not Ready; one whose
+// liveness probe fails is stopped and marked as Failed, leaving it to the
+// manager to restart it by its restart policy. Tasks without a readiness probe are
+// ready as soon as they run.
+func (w *Worker) RunProbes() {
+	states := make(map[string]*probeState)
+	for {
+		w.runProbes(states)
+		time.Sleep(time.Second)
+	}
+}
+
+func (w *Worker) runProbes(states map[string]*probeState) {
+	seen := make(map[string]bool)
+	for _, t := range w.GetTasks() {
+		if t.State != task.Running || t.ContainerId == "" {
+			continue
+		}
+		liveness, readiness := t.Probes()
+
+		ready := true
+		if readiness != nil {
+			key := t.ContainerId + "/readiness"
+			seen[key] = true
+			st, ok := states[key]
+			if !ok {
+				st = &probeState{}
+				states[key] = st
+			}
+			w.probe(*t, *readiness, st, "readiness")
+			ready = st.passing
+		}
+		if t.Ready != ready {
+			w.setReady(t, ready)
+		}
+
+		if liveness != nil {
+			key := t.ContainerId + "/liveness"
+			seen[key] = true
+			st, ok := states[key]
+			if !ok {
+				st = &probeState{passing: true}
+				states[key] = st
+			}
+			err := w.probe(*t, *liveness, st, "liveness")
+			if !st.passing {
+				w.failTask(*t, fmt.Errorf("liveness probe failed: %v", err))
+			}
+		}
+	}
+
+	for key := range states {
+		if !seen[key] {
+			delete(states, key)
+		}
+	}
+}
+
+// probe runs p against t if it is due and returns the error of the run.
+func (w *Worker) probe(t task.Task, p task.Probe, st *probeState, kind string) error {
+	p = p.WithDefaults()
+	now := time.Now().UTC()
+	if now.Sub(t.StartTime) < time.Duration(p.InitialDelay)*time.Second {
+		return nil
+	}
+	if now.Sub(st.lastRun) < time.Duration(p.Period)*time.Second {
+		return nil
+	}
+	st.lastRun = now
+
+	err := w.runProbe(t, p)
+	was := st.passing
+	if st.record(err == nil, p) != was {
+		log.Printf("%s probe of task %v is now passing=%v (%v)\n", kind, t.ID, st.passing, err)
+	}
+	return err
+}
+
+func (w *Worker) runProbe(t task.Task, p task.Probe) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.Timeout)*time.Second)
+	defer cancel()
+
+	switch {
+	case p.HTTPGet != nil:
+		return httpCheck(ctx, t, p.HTTPGet)
+	case p.TCPSocket != nil:
+		addr, err := hostAddr(t, p.TCPSocket.Port)
+		if err != nil {
+			return err
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	default:
+		rt, err := w.runtimeFor(t)
+		if err != nil {
+			return err
+		}
+		return execCheck(ctx, rt, t, p.Exec)
+	}
+}
+
+// hostAddr returns the address on the worker that reaches a port of the
+// task: the published host port, or the port itself for tasks running as
+// plain processes. An empty port picks the first published one.
+func hostAddr(t task.Task, port string) (string, error) {
+	if t.Driver == "exec" {
+		_, p := nat.SplitProtoPort(port)
+		return net.JoinHostPort("127.0.0.1", p), nil
+	}
+	if port == "" {
+		for _, bindings := range t.HostPorts {
+			if len(bindings) > 0 {
+				return net.JoinHostPort("127.0.0.1", bindings[0].HostPort), nil
+			}
+		}
+		return "", fmt.Errorf("task %v has no published ports", t.ID)
+	}
+	bindings := t.HostPorts[nat.Port(port)]
+	if len(bindings) == 0 {
+		return "", fmt.Errorf("port %s is not published", port)
+	}
+	return net.JoinHostPort("127.0.0.1", bindings[0].HostPort), nil
+}
+
+func httpCheck(ctx context.Context, t task.Task, hook *task.HTTPGetHook) error {
+	addr, err := hostAddr(t, hook.Port)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://%s%s", addr, hook.Path)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// execCheck runs cmd inside the task and fails unless it exits with 0
+// before ctx is done.
+func execCheck(ctx context.Context, rt task.Runtime, t task.Task, cmd []string) error {
+	ex, ok := rt.(task.Execer)
+	if !ok {
+		return ErrExecNotSupported
+	}
+	session, err := ex.Exec(ctx, t.ContainerId, task.ExecOptions{Cmd: cmd})
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		session.Conn.Close()
+	}()
+	io.Copy(io.Discard, session.Reader)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command %v timed out", cmd)
+	}
+
+	result, err := ex.ExecInspect(session.ID)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("command %v exited with code %d", cmd, result.ExitCode)
+	}
+	return nil
+}
+
+// setReady records the readiness of a task on the latest copy in the
+// store, as other loops of the worker update the task too.
+func (w *Worker) setReady(t *task.Task, ready bool) {
+	result, err := w.Db.Get(t.ID.String())
+	if err != nil {
+		return
+	}
+	latest := result.(*task.Task)
+	if latest.ContainerId != t.ContainerId {
+		return
+	}
+	latest.Ready = ready
+	w.Db.Put(latest.ID.String(), latest)
+}
+
+// failTask stops the container of a task whose liveness probe failed and
+// records the task as Failed on the latest copy in the store.
+func (w *Worker) failTask(t task.Task, cause error) {
+	log.Printf("Stopping task %v: %v\n", t.ID, cause)
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		log.Printf("Error stopping task %v: %v\n", t.ID, err)
+		return
+	}
+	result := rt.Stop(t.ContainerId, task.StopOptions{Signal: t.StopSignal, Timeout: t.StopTimeout})
+	if result.Error != nil {
+		log.Printf("Error stopping container %v: %v\n", t.ContainerId, result.Error)
+	}
+
+	got, err := w.Db.Get(t.ID.String())
+	if err != nil {
+		return
+	}
+	latest := got.(*task.Task)
+	if latest.ContainerId != t.ContainerId || latest.State != task.Running {
+		return
+	}
+	latest.State = task.Failed
+	latest.Reason = task.ReasonLivenessProbeFailed
+	latest.Error = cause.Error()
+	latest.Ready = false
+	latest.FinishTime = time.Now().UTC()
+	w.Db.Put(latest.ID.String(), latest)
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/utsab818/my-orchestrator/node"
+	"github.com/utsab818/my-orchestrator/stats"
+)
+
+// heartbeatInterval is how often a registered worker reports to the
+// manager.
+const heartbeatInterval = 10 * time.Second
+
+var managerClient = &http.Client{Timeout: 5 * time.Second}
+
+// Join registers the worker with the manager, announcing that its API is
+// reachable at address, and then sends the manager a heartbeat with its
+// stats every heartbeatInterval. The worker registers again whenever the
+// manager no longer knows it, for example after the manager restarted.
+func (w *Worker) Join(manager string, address string, labels map[string]string, taints []node.Taint) {
+	registered := false
+	for {
+		var err error
+		if !registered {
+			err = w.register(manager, address, labels, taints)
+			registered = err == nil
+		} else {
+			registered, err = w.heartbeat(manager, address)
+		}
+		if err != nil {
+			log.Printf("Error reaching manager %s: %v\n", manager, err)
+		}
+		time.Sleep(heartbeatInterval)
+	}
+}
+
+func (w *Worker) register(manager string, address string, labels map[string]string, taints []node.Taint) error {
+	s := w.Stats
+	if s == nil {
+		s = stats.GetStats()
+	}
+	reg := node.Registration{
+		Name:    w.Name,
+		Address: address,
+		Cores:   runtime.NumCPU(),
+		Memory:  int(s.MemTotalKb()),
+		Disk:    int(s.DiskTotal()),
+		Labels:  labels,
+		Taints:  taints,
+	}
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/nodes", manager)
+	resp, err := managerClient.Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registration returned %s", resp.Status)
+	}
+	log.Printf("Registered with manager %s as %s\n", manager, address)
+	return nil
+}
+
+// heartbeat reports the worker's stats to the manager and returns whether
+// the manager still knows the worker.
+func (w *Worker) heartbeat(manager string, address string) (bool, error) {
+	var s stats.Stats
+	if w.Stats != nil {
+		s = *w.Stats
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return true, err
+	}
+
+	url := fmt.Sprintf("http://%s/nodes/%s/heartbeat", manager, address)
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(data))
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := managerClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		log.Printf("Manager %s does not know this worker, registering again\n", manager)
+		return false, nil
+	default:
+		return true, fmt.Errorf("heartbeat returned %s", resp.Status)
+	}
+}
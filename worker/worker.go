@@ -1,11 +1,17 @@
 package worker
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"sync"
 	"time"
 
+	"github.com/docker/docker/api/types/registry"
 	"github.com/golang-collections/collections/queue"
 	"github.com/utsab818/my-orchestrator/stats"
 	"github.com/utsab818/my-orchestrator/store"
@@ -13,17 +19,39 @@ import (
 )
 
 type Worker struct {
-	Name      string
-	Queue     *queue.Queue
+	Name  string
+	Queue *queue.Queue
+	// queueMu guards Queue, which the API adds to while RunTasks takes
+	// from it.
+	queueMu   sync.Mutex
 	Db        store.Store
 	TaskCount int
 	Stats     *stats.Stats
+	Runtime   task.Runtime
+	Drivers   map[string]task.Runtime
+	// Host directories tasks are allowed to bind mount from.
+	BindAllowlist []string
+	// Registry credentials, keyed by registry host or by the secret name a
+	// task refers to in ImagePullSecret.
+	RegistryAuth map[string]registry.AuthConfig
+
+	RunInterval    time.Duration // how often queued tasks are started or stopped
+	UpdateInterval time.Duration // how often the state of running tasks is checked
 }
 
-func New(name string, taskDbType string) *Worker {
+// Default intervals of the loops that run queued tasks and check the state
+// of running ones.
+const (
+	DefaultRunInterval    = 10 * time.Second
+	DefaultUpdateInterval = 15 * time.Second
+)
+
+func New(name string, taskDbType string, runtimeType string) *Worker {
 	w := Worker{
-		Name:  name,
-		Queue: queue.New(),
+		Name:           name,
+		Queue:          queue.New(),
+		RunInterval:    DefaultRunInterval,
+		UpdateInterval: DefaultUpdateInterval,
 	}
 
 	var s store.Store
@@ -41,6 +69,32 @@ func New(name string, taskDbType string) *Worker {
 	}
 
 	w.Db = s
+
+	var rt task.Runtime
+	switch runtimeType {
+	case "docker":
+		rt, err = task.NewDocker()
+	case "fake":
+		rt = task.NewFake()
+	default:
+		err = fmt.Errorf("unknown runtime %q", runtimeType)
+	}
+
+	if err != nil {
+		log.Fatalf("unable to create runtime: %v", err)
+	}
+
+	w.Runtime = rt
+
+	ex, err := task.NewExec(fmt.Sprintf("%s_logs", name))
+	if err != nil {
+		log.Fatalf("unable to create exec driver: %v", err)
+	}
+
+	w.Drivers = map[string]task.Runtime{
+		runtimeType: rt,
+		"exec":      ex,
+	}
 	return &w
 }
 
@@ -62,7 +116,9 @@ func (w *Worker) GetTasks() []*task.Task {
 }
 
 func (w *Worker) RunTask() task.DockerResult {
+	w.queueMu.Lock()
 	t := w.Queue.Dequeue()
+	w.queueMu.Unlock()
 	if t == nil {
 		log.Println("No tasks in the queue")
 		return task.DockerResult{Error: nil}
@@ -114,46 +170,208 @@ func (w *Worker) RunTask() task.DockerResult {
 	return result
 }
 
-func (w Worker) AddTask(t task.Task) {
+func (w *Worker) AddTask(t task.Task) {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
 	w.Queue.Enqueue(t)
 }
 
+// runtimeFor returns the driver requested by the task, falling back to the
+// worker's default runtime when the task does not name one.
+func (w *Worker) runtimeFor(t task.Task) (task.Runtime, error) {
+	if t.Driver == "" {
+		return w.Runtime, nil
+	}
+	rt, ok := w.Drivers[t.Driver]
+	if !ok {
+		return nil, fmt.Errorf("driver %q is not available on worker %s", t.Driver, w.Name)
+	}
+	return rt, nil
+}
+
 func (w *Worker) StartTask(t task.Task) task.DockerResult {
 	t.StartTime = time.Now().UTC()
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		log.Printf("Error running task %v: %v\n", t.ID, err)
+		t.State = task.Failed
+		t.Reason = task.ReasonFailed
+		t.Error = err.Error()
+		w.Db.Put(t.ID.String(), &t)
+		return task.DockerResult{Error: err}
+	}
+
+	err = w.checkBinds(t)
+	if err != nil {
+		log.Printf("Error running task %v: %v\n", t.ID, err)
+		t.State = task.Failed
+		t.Reason = task.ReasonFailed
+		t.Error = err.Error()
+		w.Db.Put(t.ID.String(), &t)
+		return task.DockerResult{Error: err}
+	}
+
 	config := task.NewConfig(&t)
-	d := task.NewDocker(config)
-	result := d.Run()
+	config.RegistryAuth, err = w.registryAuth(t)
+	if err != nil {
+		log.Printf("Error running task %v: %v\n", t.ID, err)
+		t.State = task.Failed
+		t.Reason = task.ReasonImagePullFailed
+		t.Error = err.Error()
+		w.Db.Put(t.ID.String(), &t)
+		return task.DockerResult{Error: err}
+	}
+
+	result := rt.Run(config)
 	if result.Error != nil {
 		log.Printf("Error running task %v: %v\n", t.ID, result.Error)
 		t.State = task.Failed
+		t.Reason = task.ReasonFailed
+		var pullErr *task.ImagePullError
+		if errors.As(result.Error, &pullErr) {
+			t.Reason = task.ReasonImagePullFailed
+		}
+		t.Error = result.Error.Error()
 		w.Db.Put(t.ID.String(), &t)
 		return result
 	}
 
 	t.ContainerId = result.ContainerId
 	t.State = task.Running
+	t.Ready = false
+	// Probes need the published ports before the next task update.
+	if resp := rt.Inspect(t.ContainerId); resp.Container != nil {
+		t.HostPorts = resp.Container.NetworkSettings.NetworkSettingsBase.Ports
+	}
 	w.Db.Put(t.ID.String(), &t)
 	return result
 }
 
 func (w *Worker) StopTask(t task.Task) task.DockerResult {
-	config := task.NewConfig(&t)
-	d := task.NewDocker(config)
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		log.Printf("Error stopping task %v: %v\n", t.ID, err)
+		return task.DockerResult{Error: err}
+	}
 
-	result := d.Stop(t.ContainerId)
+	if t.PreStop != nil {
+		err = w.runPreStop(rt, t)
+		if err != nil {
+			log.Printf("PreStop hook for task %v failed: %v\n", t.ID, err)
+		}
+	}
+
+	result := rt.Stop(t.ContainerId, task.StopOptions{Signal: t.StopSignal, Timeout: t.StopTimeout})
 	if result.Error != nil {
 		log.Printf("Error stopping container %v: %v\n", t.ContainerId, result.Error)
 	}
+	t.StopResult = result.Result
+	w.removeVolumes(rt, t)
+
 	t.FinishTime = time.Now().UTC()
 	t.State = task.Completed
+	t.Reason = task.ReasonStopped
+	t.Ready = false
 	w.Db.Put(t.ID.String(), &t)
 	log.Printf("Stopped and removed container %v for task %v\n", t.ContainerId, t.ID)
 	return result
 }
 
+// runPreStop runs the task's PreStop hook, giving the application a chance
+// to drain before it receives its stop signal. The stop goes ahead whether
+// or not the hook succeeds.
+func (w *Worker) runPreStop(rt task.Runtime, t task.Task) error {
+	timeout := time.Duration(task.DefaultHookTimeout) * time.Second
+	if t.PreStop.Timeout > 0 {
+		timeout = time.Duration(t.PreStop.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if t.PreStop.HTTPGet != nil {
+		if err := httpCheck(ctx, t, t.PreStop.HTTPGet); err != nil {
+			return err
+		}
+		log.Printf("PreStop hook %s for task %v succeeded\n", t.PreStop.HTTPGet.Path, t.ID)
+		return nil
+	}
+
+	if err := execCheck(ctx, rt, t, t.PreStop.Exec); err != nil {
+		return err
+	}
+	log.Printf("PreStop hook %v for task %v completed\n", t.PreStop.Exec, t.ID)
+	return nil
+}
+
+// registryAuth returns the encoded credentials used to pull the task's
+// image. A secret named by the task must exist on the worker; otherwise the
+// credentials for the image's registry are used when configured.
+func (w *Worker) registryAuth(t task.Task) (string, error) {
+	var auth registry.AuthConfig
+	if t.ImagePullSecret != "" {
+		a, ok := w.RegistryAuth[t.ImagePullSecret]
+		if !ok {
+			return "", fmt.Errorf("image pull secret %q is not configured on worker %s", t.ImagePullSecret, w.Name)
+		}
+		auth = a
+	} else {
+		a, ok := w.RegistryAuth[task.RegistryHost(t.Image)]
+		if !ok {
+			return "", nil
+		}
+		auth = a
+	}
+	return registry.EncodeAuthConfig(auth)
+}
+
+// LoadRegistryAuth reads registry credentials from a JSON file mapping a
+// registry host or secret name to its credentials.
+func LoadRegistryAuth(filename string) (map[string]registry.AuthConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	auth := make(map[string]registry.AuthConfig)
+	err = json.Unmarshal(data, &auth)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", filename, err)
+	}
+	return auth, nil
+}
+
+func (w *Worker) checkBinds(t task.Task) error {
+	for _, v := range t.Volumes {
+		if v.Type == task.VolumeTypeBind && !task.BindAllowed(v.Source, w.BindAllowlist) {
+			return fmt.Errorf("bind mount of %s is not allowed on worker %s", v.Source, w.Name)
+		}
+	}
+	return nil
+}
+
+// removeVolumes deletes the named volumes of a stopped task whose policy
+// is Delete. Volumes are retained by default.
+func (w *Worker) removeVolumes(rt task.Runtime, t task.Task) {
+	vr, ok := rt.(task.VolumeRemover)
+	if !ok {
+		return
+	}
+	for _, v := range t.Volumes {
+		if v.Type != task.VolumeTypeVolume || v.Policy != task.VolumeDelete {
+			continue
+		}
+		err := vr.RemoveVolume(v.Source)
+		if err != nil {
+			log.Printf("Error removing volume %s for task %v: %v\n", v.Source, t.ID, err)
+		}
+	}
+}
+
 func (w *Worker) RunTasks() {
 	for {
-		if w.Queue.Len() != 0 {
+		w.queueMu.Lock()
+		queued := w.Queue.Len()
+		w.queueMu.Unlock()
+		if queued != 0 {
 			result := w.RunTask()
 			if result.Error != nil {
 				log.Printf("Error running task: %v\n", result.Error)
@@ -161,15 +379,76 @@ func (w *Worker) RunTasks() {
 		} else {
 			log.Printf("No tasks to process currently.\n")
 		}
-		log.Println("Sleeping for 10 seconds")
-		time.Sleep(10 * time.Second)
+		log.Printf("Sleeping for %v\n", w.RunInterval)
+		time.Sleep(w.RunInterval)
 	}
 }
 
 func (w *Worker) InspectTask(t task.Task) task.DockerInspectResponse {
-	config := task.NewConfig(&t)
-	d := task.NewDocker(config)
-	return d.Inspect(t.ContainerId)
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		return task.DockerInspectResponse{Error: err}
+	}
+	return rt.Inspect(t.ContainerId)
+}
+
+// TaskLogs writes the output of a task's container to stdout and stderr
+// using the runtime the task was started with.
+func (w *Worker) TaskLogs(ctx context.Context, taskID string, opts task.LogOptions, stdout, stderr io.Writer) error {
+	result, err := w.Db.Get(taskID)
+	if err != nil {
+		return err
+	}
+	t := *result.(*task.Task)
+	if t.ContainerId == "" {
+		return fmt.Errorf("task %s has no container", taskID)
+	}
+
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		return err
+	}
+	return rt.Logs(ctx, t.ContainerId, opts, stdout, stderr)
+}
+
+var ErrExecNotSupported = errors.New("runtime does not support exec")
+
+// ExecTask starts cmd inside the container of a running task.
+func (w *Worker) ExecTask(ctx context.Context, taskID string, opts task.ExecOptions) (*task.ExecSession, error) {
+	ex, t, err := w.execer(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if t.State != task.Running {
+		return nil, fmt.Errorf("task %s is not running", taskID)
+	}
+	return ex.Exec(ctx, t.ContainerId, opts)
+}
+
+func (w *Worker) InspectExec(taskID string, execID string) (task.ExecResult, error) {
+	ex, _, err := w.execer(taskID)
+	if err != nil {
+		return task.ExecResult{}, err
+	}
+	return ex.ExecInspect(execID)
+}
+
+func (w *Worker) execer(taskID string) (task.Execer, task.Task, error) {
+	result, err := w.Db.Get(taskID)
+	if err != nil {
+		return nil, task.Task{}, err
+	}
+	t := *result.(*task.Task)
+
+	rt, err := w.runtimeFor(t)
+	if err != nil {
+		return nil, t, err
+	}
+	ex, ok := rt.(task.Execer)
+	if !ok {
+		return nil, t, ErrExecNotSupported
+	}
+	return ex, t, nil
 }
 
 func (w *Worker) updateTasks() {
@@ -189,12 +468,19 @@ func (w *Worker) updateTasks() {
 			if resp.Container == nil {
 				log.Printf("No container for running task %s\n", t.ID)
 				t.State = task.Failed
+				t.Reason = task.ReasonFailed
+				t.Error = "container not found"
+				if resp.Error != nil {
+					t.Error = resp.Error.Error()
+				}
 				w.Db.Put(t.ID.String(), t)
+				continue
 			}
 
-			if resp.Container.State.Status == "exited" {
+			if resp.Container.State.Status == "exited" || resp.Container.State.Status == "dead" {
 				log.Printf("Container for task %s in non-running state %s", t.ID, resp.Container.State.Status)
-				t.State = task.Failed
+				t.Terminated(resp.Container.State)
+				log.Printf("Task %s finished with exit code %d (%s)\n", t.ID, t.ExitCode, t.Reason)
 				w.Db.Put(t.ID.String(), t)
 			}
 
@@ -209,7 +495,7 @@ func (w *Worker) UpdateTasks() {
 		log.Println("Checking status of tasks")
 		w.updateTasks()
 		log.Println("Task updates completed")
-		log.Println("Sleeping for 15 seconds")
-		time.Sleep(15 * time.Second)
+		log.Printf("Sleeping for %v\n", w.UpdateInterval)
+		time.Sleep(w.UpdateInterval)
 	}
 }